@@ -6,6 +6,35 @@ import (
 	"net/http"
 )
 
+// List of the error types returned to the clients. They are stable and can
+// be used by the clients to identify an error
+const (
+	TypeBadRequest   = "bad_request"
+	TypeMissingParam = "missing_param"
+	TypeInvalidParam = "invalid_param"
+	TypeUnknownParam = "unknown_param"
+	TypeValidation   = "validation_failed"
+	TypeUnauthorized = "unauthorized"
+	TypeForbidden    = "forbidden"
+	TypeNotFound     = "not_found"
+	TypeConflict     = "conflict"
+	TypeTooLarge     = "payload_too_large"
+	TypeServerError  = "server_error"
+	TypeUnavailable  = "service_unavailable"
+)
+
+// defaultTypes contains the type used for each HTTP code when none is provided
+var defaultTypes = map[int]string{
+	http.StatusBadRequest:            TypeBadRequest,
+	http.StatusUnauthorized:          TypeUnauthorized,
+	http.StatusForbidden:             TypeForbidden,
+	http.StatusNotFound:              TypeNotFound,
+	http.StatusConflict:              TypeConflict,
+	http.StatusRequestEntityTooLarge: TypeTooLarge,
+	http.StatusInternalServerError:   TypeServerError,
+	http.StatusServiceUnavailable:    TypeUnavailable,
+}
+
 // Error represents an error with a code attached.
 type Error interface {
 	error
@@ -15,6 +44,16 @@ type Error interface {
 type ApiError struct {
 	error
 	ErrorCode int
+
+	// Type contains a stable and machine-readable string identifying the
+	// error. It is returned to the clients as "code"
+	Type string
+
+	// Field contains the name of the param that caused the error, if any
+	Field string
+
+	// Details contains any additional data that may help the clients
+	Details interface{}
 }
 
 func (err *ApiError) Code() int {
@@ -28,21 +67,107 @@ func (err *ApiError) Code() int {
 // NewError returns an error with an associated code
 func NewError(code int, message string, args ...interface{}) error {
 	fullMessage := fmt.Sprintf(message, args...)
-	return &ApiError{errors.New(fullMessage), code}
+
+	typ, found := defaultTypes[code]
+	if !found {
+		typ = TypeServerError
+		if code < http.StatusInternalServerError {
+			typ = TypeBadRequest
+		}
+	}
+
+	return &ApiError{
+		error:     errors.New(fullMessage),
+		ErrorCode: code,
+		Type:      typ,
+	}
 }
 
 // NewServerError returns an Internal Error.
 func NewServerError(message string, args ...interface{}) error {
-	return NewError(http.StatusInternalServerError, message, args)
+	return NewError(http.StatusInternalServerError, message, args...)
 }
 
 // NewBadRequest returns an error caused by a user. Example: A missing param
 func NewBadRequest(message string, args ...interface{}) error {
-	return NewError(http.StatusBadRequest, message, args)
+	return NewError(http.StatusBadRequest, message, args...)
+}
+
+// NewMissingParam returns a Bad Request error caused by a required param
+// that has not been provided
+func NewMissingParam(field string) error {
+	err := NewBadRequest("parameter [%s] missing", field).(*ApiError)
+	err.Type = TypeMissingParam
+	err.Field = field
+	return err
+}
+
+// NewInvalidParam returns a Bad Request error caused by a param having an
+// invalid value
+func NewInvalidParam(field string, message string, args ...interface{}) error {
+	err := NewBadRequest(message, args...).(*ApiError)
+	err.Type = TypeInvalidParam
+	err.Field = field
+	return err
+}
+
+// NewUnknownParam returns a Bad Request error caused by a param that is not
+// accepted by the endpoint
+func NewUnknownParam(field string) error {
+	err := NewBadRequest("parameter [%s] is not allowed", field).(*ApiError)
+	err.Type = TypeUnknownParam
+	err.Field = field
+	return err
+}
+
+// FieldError represents a param that failed the validation
+type FieldError struct {
+	Field   string `json:"field"`
+	Source  string `json:"source"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// NewValidationError returns a Bad Request error listing all the params that
+// failed the validation. The list is returned to the clients as "details"
+func NewValidationError(errs []*FieldError) error {
+	err := NewBadRequest("%d parameter(s) failed the validation", len(errs)).(*ApiError)
+	err.Type = TypeValidation
+	err.Details = errs
+	return err
+}
+
+// NewUnauthorized returns an error caused by a missing or invalid
+// authentication. Example: An expired session token
+func NewUnauthorized(message string, args ...interface{}) error {
+	return NewError(http.StatusUnauthorized, message, args...)
+}
+
+// NewForbidden returns an error caused by a user lacking the rights to
+// perform an action. Example: A user without permissions adding an article
+func NewForbidden(message string, args ...interface{}) error {
+	return NewError(http.StatusForbidden, message, args...)
 }
 
 // NewConflict returns an error caused by a conflict with the current state
 // of the app. Example: A duplicate slug
 func NewConflict(message string, args ...interface{}) error {
-	return NewError(http.StatusConflict, message, args)
+	return NewError(http.StatusConflict, message, args...)
+}
+
+// NewTooLarge returns an error caused by a request body bigger than allowed
+func NewTooLarge(message string, args ...interface{}) error {
+	return NewError(http.StatusRequestEntityTooLarge, message, args...)
+}
+
+// NewServiceUnavailable returns an error caused by the app not being able to
+// handle requests. Example: The database is unreachable
+func NewServiceUnavailable(message string, args ...interface{}) error {
+	return NewError(http.StatusServiceUnavailable, message, args...)
+}
+
+// NewNotFound returns an error caused by a missing resource.
+// Example: An article that does not exist
+func NewNotFound(message string, args ...interface{}) error {
+	return NewError(http.StatusNotFound, message, args...)
 }
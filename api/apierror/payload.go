@@ -0,0 +1,66 @@
+package apierror
+
+import "net/http"
+
+// ServerErrorMessage is the message returned to the clients for all the
+// Internal Errors, since their real message may leak sensitive data
+const ServerErrorMessage = "Something went wrong"
+
+// Payload represents the JSON envelope returned to the clients when a
+// request fails:
+//
+//	{
+//	  "error": {
+//	    "code": "invalid_param",
+//	    "message": "value [42] for parameter [slug] is invalid",
+//	    "field": "slug"
+//	  },
+//	  "request_id": "1a2b3c4d"
+//	}
+//
+// "field" and "details" are omitted when empty.
+type Payload struct {
+	Error     *PayloadError `json:"error"`
+	RequestID string        `json:"request_id"`
+}
+
+// PayloadError contains the data of an error that can be safely returned
+// to the clients
+type PayloadError struct {
+	Code    string      `json:"code"`
+	Message string      `json:"message"`
+	Field   string      `json:"field,omitempty"`
+	Details interface{} `json:"details,omitempty"`
+}
+
+// NewPayload turns an error into an object that is safe to be returned by
+// the API. Errors that are not an *ApiError are treated as Internal Errors
+func NewPayload(e error, requestID string) *Payload {
+	err, casted := e.(*ApiError)
+	if !casted {
+		err = NewServerError("%s", e.Error()).(*ApiError)
+	}
+
+	pld := &Payload{
+		RequestID: requestID,
+		Error: &PayloadError{
+			Code:    err.Type,
+			Message: err.Error(),
+			Field:   err.Field,
+			Details: err.Details,
+		},
+	}
+
+	if pld.Error.Code == "" {
+		pld.Error.Code = defaultTypes[err.Code()]
+	}
+
+	if err.Code() == http.StatusInternalServerError {
+		pld.Error = &PayloadError{
+			Code:    TypeServerError,
+			Message: ServerErrorMessage,
+		}
+	}
+
+	return pld
+}
@@ -2,7 +2,10 @@ package app
 
 import (
 	"fmt"
+	"os"
+	"time"
 
+	"github.com/Nivl/api.melvin.la/api/logger"
 	"github.com/bsphere/le_go"
 	"github.com/kelseyhightower/envconfig"
 	"gopkg.in/mgo.v2"
@@ -12,60 +15,179 @@ import (
 type Args struct {
 	Port            string `default:"5000"`
 	MongoURI        string `required:"true" envconfig:"mongo_uri"`
-	LogEntriesToken string `envconfig:"mongo_uri" envconfig:"logentries_token"`
+	LogEntriesToken string `envconfig:"logentries_token"`
 	Debug           bool   `default:"false"`
+
+	// MongoDatabase contains the name of the database to use, instead of
+	// the one of MongoURI. It allows several apps to use isolated databases
+	MongoDatabase string `envconfig:"mongo_database"`
+
+	// MongoPoolLimit contains the maximum number of sockets opened per Mongo
+	// server. Once reached, the requests wait for a socket to be released
+	MongoPoolLimit int `default:"4096" envconfig:"mongo_pool_limit"`
+
+	// LogLevel contains the minimum level of the logs to write (debug, info,
+	// warn, error)
+	LogLevel string `default:"info" envconfig:"log_level"`
+
+	// LogFormat contains the format of the logs (json, console)
+	LogFormat string `default:"json" envconfig:"log_format"`
+
+	// LogSinks contains where the logs should be written (stdout, file,
+	// syslog). LogEntries is automatically added when LogEntriesToken is set
+	LogSinks []string `default:"stdout" envconfig:"log_sinks"`
+
+	// LogBufferSize contains the number of log entries that can be waiting
+	// to be written. New entries are dropped when the buffer is full
+	LogBufferSize int `default:"1024" envconfig:"log_buffer_size"`
+
+	// LogFile contains the path of the file used by the file sink, which is
+	// rotated once it reaches LogFileMaxSize megabytes
+	LogFile           string `default:"api.log" envconfig:"log_file"`
+	LogFileMaxSize    int64  `default:"100" envconfig:"log_file_max_size"`
+	LogFileMaxBackups int    `default:"5" envconfig:"log_file_max_backups"`
+
+	// TrustedProxies contains the IPs and CIDRs of the proxies allowed to set
+	// the X-Forwarded-For and X-Real-Ip headers
+	TrustedProxies []string `envconfig:"trusted_proxies"`
+
+	// TrashRetention is the amount of time a deleted item stays in the trash
+	// before being purged
+	TrashRetention time.Duration `default:"720h" envconfig:"trash_retention"`
+
+	// SessionDuration is the amount of time a session stays valid after
+	// being created
+	SessionDuration time.Duration `default:"720h" envconfig:"session_duration"`
+
+	// Timeouts of the HTTP server. ShutdownTimeout is the amount of time
+	// given to the in-flight requests to complete and to the logs to be
+	// flushed when the server stops. It needs to be lower than the 10s Docker
+	// waits before killing the app
+	ReadTimeout     time.Duration `default:"15s" envconfig:"read_timeout"`
+	WriteTimeout    time.Duration `default:"30s" envconfig:"write_timeout"`
+	IdleTimeout     time.Duration `default:"120s" envconfig:"idle_timeout"`
+	ShutdownTimeout time.Duration `default:"8s" envconfig:"shutdown_timeout"`
+}
+
+// BuildInfo contains the information about the binary being ran. They are
+// set at compile time
+type BuildInfo struct {
+	Version   string
+	Commit    string
+	BuildTime string
 }
 
-// Context represent the global context of the app
+// Context contains the dependencies of the app. It is created in main() and
+// given to the components, so several apps can run in the same process
 type Context struct {
 	DB         *mgo.Database
 	Session    *mgo.Session
 	Params     Args
 	LogEntries *le_go.Logger
-}
+	Build      BuildInfo
 
-var _context *Context
+	// Logger is the logger of the app, configured using its params. It is
+	// flushed and closed when the app is destroyed
+	Logger *logger.Logger
 
-// InitContex initializes the app context
-func InitContex() *Context {
-	if _context != nil {
-		panic("Context already exists")
-	}
+	// StartedAt contains the date the context has been created at
+	StartedAt time.Time
 
-	_context = new(Context)
+	// IndexesEnsured is set to true once all the indexes have been created
+	IndexesEnsured bool
+
+	// onDestroy contains the functions to call when the context is destroyed
+	onDestroy []func()
+}
 
-	if err := envconfig.Process("api", &_context.Params); err != nil {
-		panic(err)
+// ParseArgs returns the args of the app, read from the environment
+func ParseArgs() (Args, error) {
+	params := Args{}
+	err := envconfig.Process("api", &params)
+	return params, err
+}
+
+// New creates an app using the given args. Each app has its own connection
+// to the database, and needs to be destroyed once not used anymore
+func New(params Args) (*Context, error) {
+	ctx := &Context{
+		Params:    params,
+		StartedAt: time.Now(),
 	}
 
-	// Setup database
-	session, err := mgo.Dial(_context.Params.MongoURI)
+	// Setup database. The stats are needed to tune the size of the pool
+	mgo.SetStats(true)
+	session, err := mgo.Dial(params.MongoURI)
 	if err != nil {
-		fmt.Println("Cannot start mongo")
-		panic(err)
+		return nil, fmt.Errorf("cannot start mongo: %s", err.Error())
 	}
-	_context.Session = session
-	_context.Session.SetMode(mgo.Monotonic, true)
-	_context.DB = session.DB("")
+	ctx.Session = session
+	ctx.Session.SetMode(mgo.Monotonic, true)
+	ctx.Session.SetPoolLimit(params.MongoPoolLimit)
+	ctx.DB = session.DB(params.MongoDatabase)
 
 	// LogEntries
-	if _context.Params.LogEntriesToken != "" {
-		_context.LogEntries, err = le_go.Connect(_context.Params.LogEntriesToken)
+	if params.LogEntriesToken != "" {
+		ctx.LogEntries, err = le_go.Connect(params.LogEntriesToken)
 		if err != nil {
-			panic(err)
+			ctx.Destroy()
+			return nil, err
 		}
 	}
 
-	return _context
+	ctx.Logger, err = newLogger(ctx)
+	if err != nil {
+		ctx.Destroy()
+		return nil, err
+	}
+
+	return ctx, nil
+}
+
+// newLogger returns a logger configured using the params of the app
+func newLogger(ctx *Context) (*logger.Logger, error) {
+	params := &ctx.Params
+	sinks := append([]string{}, params.LogSinks...)
+
+	// LogEntries is used as soon as a token is provided
+	if ctx.LogEntries != nil {
+		sinks = append(sinks, logger.SinkLogEntries)
+	}
+
+	return logger.NewFromConfig(logger.Config{
+		Level:          params.LogLevel,
+		Format:         params.LogFormat,
+		Debug:          params.Debug,
+		Sinks:          sinks,
+		BufferSize:     params.LogBufferSize,
+		File:           params.LogFile,
+		FileMaxSize:    params.LogFileMaxSize << 20,
+		FileMaxBackups: params.LogFileMaxBackups,
+		LogEntries:     ctx.LogEntries,
+	})
 }
 
-// GetContext returns the current app context.
-func GetContext() *Context {
-	return _context
+// OnDestroy registers a function to be called when the context is
+// destroyed, before the connections are closed
+func (ctx *Context) OnDestroy(fn func()) {
+	ctx.onDestroy = append(ctx.onDestroy, fn)
 }
 
 // Destroy clears the context when the app is quiting
 func (ctx *Context) Destroy() {
+	// The functions are called in reverse order, like defers
+	for i := len(ctx.onDestroy) - 1; i >= 0; i-- {
+		ctx.onDestroy[i]()
+	}
+	ctx.onDestroy = nil
+
+	// The logger may be writing to LogEntries, so it has to be closed first
+	if ctx.Logger != nil {
+		if err := ctx.Logger.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "logger: %s\n", err.Error())
+		}
+	}
+
 	if ctx.Session != nil {
 		ctx.Session.Close()
 	}
@@ -11,3 +11,12 @@ func GetDateForDB(t time.Time) time.Time {
 func GetDateForJSON(t time.Time) string {
 	return t.UTC().Format(ISO8601)
 }
+
+// ParseDate parses a date using either the ISO8601 or the RFC3339 format
+func ParseDate(value string) (time.Time, error) {
+	t, err := time.Parse(ISO8601, value)
+	if err != nil {
+		t, err = time.Parse(time.RFC3339, value)
+	}
+	return t, err
+}
@@ -0,0 +1,19 @@
+package testhelpers
+
+import "github.com/Nivl/api.melvin.la/api/app"
+
+// NewApp creates an app using the args read from the environment. The given
+// functions can be used to change the args before the app is created, to
+// use another database for example
+func NewApp(configure ...func(*app.Args)) (*app.Context, error) {
+	params, err := app.ParseArgs()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, fn := range configure {
+		fn(&params)
+	}
+
+	return app.New(params)
+}
@@ -3,37 +3,41 @@ package testhelpers
 import (
 	"sync"
 	"testing"
+
+	mgo "gopkg.in/mgo.v2"
 )
 
 // FullyDeletable represents an objects that can be deleted from the database
 type FullyDeletable interface {
-	FullyDelete() error
+	FullyDelete(db *mgo.Database) error
 }
 
 var _models = &savedModels{
-	list: make(map[testing.TB]map[interface{}]bool),
+	list: make(map[testing.TB]map[interface{}]*mgo.Database),
 }
 
-// savedModels represents a list of models grouped by Test
+// savedModels represents a list of models grouped by Test, along with the
+// database they have been saved in.
 // Since tests are run in parallel, we need to use mutexes
 type savedModels struct {
 	sync.Mutex
-	list map[testing.TB]map[interface{}]bool
+	list map[testing.TB]map[interface{}]*mgo.Database
 }
 
 // Push adds a new model to the list
-func (sm *savedModels) Push(t testing.TB, obj FullyDeletable) {
-	_models.Lock()
-	defer _models.Unlock()
+func (sm *savedModels) Push(t testing.TB, db *mgo.Database, obj FullyDeletable) {
+	sm.Lock()
+	defer sm.Unlock()
 
 	if _, ok := sm.list[t]; !ok {
-		sm.list[t] = make(map[interface{}]bool, 0)
+		sm.list[t] = make(map[interface{}]*mgo.Database, 0)
 	}
 
-	sm.list[t][obj] = true
+	sm.list[t][obj] = db
 }
 
-// Push adds a new model to the list
+// Purge deletes all the models saved by the given test, and removes them
+// from the list
 func (sm *savedModels) Purge(t testing.TB) {
 	sm.Lock()
 	defer sm.Unlock()
@@ -43,13 +47,13 @@ func (sm *savedModels) Purge(t testing.TB) {
 		return
 	}
 
-	for obj := range list {
+	for obj, db := range list {
 		deletable, ok := obj.(FullyDeletable)
 		if !ok {
 			t.Fatalf("could not delete saved object")
 		}
 
-		if err := deletable.FullyDelete(); err != nil {
+		if err := deletable.FullyDelete(db); err != nil {
 			t.Fatalf("could not delete saved object: %s", err)
 		}
 	}
@@ -57,9 +61,10 @@ func (sm *savedModels) Purge(t testing.TB) {
 	delete(sm.list, t)
 }
 
-// SaveModel saves a model that can be purged using PurgeModels()
-func SaveModel(t testing.TB, i FullyDeletable) {
-	_models.Push(t, i)
+// SaveModel saves a model that can be purged from the given database using
+// PurgeModels()
+func SaveModel(t testing.TB, db *mgo.Database, i FullyDeletable) {
+	_models.Push(t, db, i)
 }
 
 // PurgeModels removes all models stored for the given test
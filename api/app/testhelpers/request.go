@@ -3,19 +3,34 @@ package testhelpers
 import (
 	"bytes"
 	"encoding/json"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
+	"github.com/Nivl/api.melvin.la/api/app"
 	"github.com/Nivl/api.melvin.la/api/components/api"
 	"github.com/Nivl/api.melvin.la/api/router"
 )
 
 type RequestInfo struct {
 	Test     *testing.T
+	App      *app.Context
 	Endpoint *router.Endpoint
 	URI      string
 	Params   interface{}
+
+	// Token contains the token to send using the Authorization header
+	Token string
+
+	// Body and ContentType can be used to send a body that is not JSON.
+	// Params is ignored when Body is set
+	Body        io.Reader
+	ContentType string
+
+	// Middlewares contains the middlewares to run on every endpoint, like
+	// articles.UseStore()
+	Middlewares []router.Middleware
 }
 
 func NewRequest(info *RequestInfo) *httptest.ResponseRecorder {
@@ -30,15 +45,25 @@ func NewRequest(info *RequestInfo) *httptest.ResponseRecorder {
 		params = bytes.NewBuffer(jsonDump)
 	}
 
-	req, err := http.NewRequest(info.Endpoint.Verb, info.URI, params)
+	contentType := "application/json; charset=utf-8"
+	var body io.Reader = params
+	if info.Body != nil {
+		body = info.Body
+		contentType = info.ContentType
+	}
+
+	req, err := http.NewRequest(info.Endpoint.Verb, info.URI, body)
 	if err != nil {
 		info.Test.Fatalf("could not execute request %s", err)
 	}
 
-	req.Header.Add("Content-Type", "application/json; charset=utf-8")
+	req.Header.Add("Content-Type", contentType)
+	if info.Token != "" {
+		req.Header.Add("Authorization", "Bearer "+info.Token)
+	}
 
 	rec := httptest.NewRecorder()
-	r := api.GetRouter()
+	r := api.GetRouter(info.App, info.Middlewares...)
 	r.ServeHTTP(rec, req)
 	return rec
 }
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/Nivl/api.melvin.la/api/components/blog/articles"
+)
+
+const articlesUsage = `articles <command>
+
+commands:
+  export [-file PATH]  write all the articles as JSON, on stdout by default
+  import [-file PATH]  save the articles of an export, read from stdin by default
+
+The articles of an import are saved as they are, replacing the existing
+articles having the same ID`
+
+// exportPayload represents the result of an export to a file in the JSON
+// output
+type exportPayload struct {
+	File     string `json:"file"`
+	Exported int    `json:"exported"`
+}
+
+// articlesCmd runs the articles command
+func articlesCmd(connect connectFunc, out *output, args []string) error {
+	if len(args) == 0 {
+		return newUsageError("missing command, usage: api %s", articlesUsage)
+	}
+
+	switch args[0] {
+	case "export":
+		return articlesExport(connect, out, args[1:])
+	case "import":
+		return articlesImport(connect, out, args[1:])
+	case "-h", "-help", "--help":
+		fmt.Fprintf(os.Stdout, "usage: api %s\n", articlesUsage)
+		return errHelp
+	}
+
+	return newUsageError("unknown command [%s], usage: api %s", args[0], articlesUsage)
+}
+
+// articlesExport writes all the articles, including the trashed ones, as a
+// JSON list
+func articlesExport(connect connectFunc, out *output, args []string) error {
+	fs := newFlagSet("export")
+	file := fs.String("file", "", "file to write the articles to, instead of stdout")
+	if err := parseCommandFlags(fs, "articles export", args); err != nil {
+		return err
+	}
+
+	appCtx, err := connect()
+	if err != nil {
+		return err
+	}
+
+	arts, err := articles.Export(appCtx.DB)
+	if err != nil {
+		return err
+	}
+
+	// When no file is provided the articles are the output of the command
+	w := out.stdout
+	if *file != "" {
+		f, err := os.Create(*file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(arts); err != nil {
+		return fmt.Errorf("could not write the articles: %s", err.Error())
+	}
+
+	if *file != "" {
+		pld := &exportPayload{File: *file, Exported: len(arts)}
+		out.Print(pld, func(w io.Writer) {
+			fmt.Fprintf(w, "%d articles exported to %s\n", len(arts), *file)
+		})
+	}
+	return nil
+}
+
+// articlesImport saves the articles of an export
+func articlesImport(connect connectFunc, out *output, args []string) error {
+	fs := newFlagSet("import")
+	file := fs.String("file", "", "file to read the articles from, instead of stdin")
+	if err := parseCommandFlags(fs, "articles import", args); err != nil {
+		return err
+	}
+
+	var r io.Reader = os.Stdin
+	if *file != "" {
+		f, err := os.Open(*file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	arts := []*articles.Article{}
+	if err := json.NewDecoder(r).Decode(&arts); err != nil {
+		return newUsageError("could not read the articles: %s", err.Error())
+	}
+
+	appCtx, err := connect()
+	if err != nil {
+		return err
+	}
+
+	res, err := articles.Import(appCtx.DB, arts)
+
+	// The saved articles are printed even if the import failed
+	out.Print(res, func(w io.Writer) {
+		fmt.Fprintf(w, "%d articles created, %d updated\n", res.Created, res.Updated)
+	})
+	return err
+}
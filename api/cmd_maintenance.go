@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/Nivl/api.melvin.la/api/components/api"
+	"github.com/Nivl/api.melvin.la/api/components/blog/articles"
+)
+
+// purgePayload represents the result of purge-trash in the JSON output
+type purgePayload struct {
+	Retention string `json:"retention"`
+	Purged    int    `json:"purged"`
+}
+
+// reindex creates the indexes that do not exist yet, and prints the
+// indexes of each collection
+func reindex(connect connectFunc, out *output, args []string) error {
+	fs := newFlagSet("reindex")
+	if err := parseCommandFlags(fs, "reindex", args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return newUsageError("reindex does not take any arguments")
+	}
+
+	appCtx, err := connect()
+	if err != nil {
+		return err
+	}
+
+	if err := api.EnsureIndexes(appCtx); err != nil {
+		return err
+	}
+
+	names, err := appCtx.DB.CollectionNames()
+	if err != nil {
+		return err
+	}
+
+	pld := map[string][]string{}
+	for _, name := range names {
+		indexes, err := appCtx.DB.C(name).Indexes()
+		if err != nil {
+			return err
+		}
+
+		pld[name] = make([]string, len(indexes))
+		for i, index := range indexes {
+			pld[name][i] = index.Name
+		}
+	}
+
+	out.Print(pld, func(w io.Writer) {
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintf(w, "%s: %d indexes\n", name, len(pld[name]))
+		}
+	})
+	return nil
+}
+
+// purgeTrash fully deletes the articles that have been in the trash for
+// longer than the retention period
+func purgeTrash(connect connectFunc, out *output, args []string) error {
+	fs := newFlagSet("purge-trash")
+	retention := fs.Duration("retention", 0, "amount of time the articles stay in the trash (default: API_TRASH_RETENTION)")
+	if err := parseCommandFlags(fs, "purge-trash [-retention DURATION]", args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return newUsageError("purge-trash does not take any arguments")
+	}
+
+	if *retention < 0 {
+		return newUsageError("the retention cannot be negative")
+	}
+
+	appCtx, err := connect()
+	if err != nil {
+		return err
+	}
+
+	// The default retention is the one of the app
+	if !isFlagSet(fs, "retention") {
+		*retention = appCtx.Params.TrashRetention
+	}
+
+	purged, err := articles.NewMongoStore(appCtx.DB).PurgeTrash(*retention)
+	if err != nil {
+		return err
+	}
+
+	pld := &purgePayload{Retention: retention.String(), Purged: purged}
+	out.Print(pld, func(w io.Writer) {
+		fmt.Fprintf(w, "%d articles purged (trashed more than %s ago)\n", purged, retention.Round(time.Second))
+	})
+	return nil
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/Nivl/api.melvin.la/api/components/api"
+	"github.com/Nivl/api.melvin.la/api/migration"
+)
+
+const migrateUsage = `migrate <command>
+
+commands:
+  status              list the migrations and their state
+  up [N]              apply the N next pending migrations (default: all)
+  down [N]            revert the N last applied migrations (default: 1)
+  dry-run up|down [N] print what up or down would do, without running it`
+
+// migrationPayload represents a migration in the JSON output
+type migrationPayload struct {
+	Version     int64      `json:"version"`
+	Description string     `json:"description"`
+	AppliedAt   *time.Time `json:"applied_at,omitempty"`
+}
+
+// migrationsPayload represents the result of up and down in the JSON output
+type migrationsPayload struct {
+	DryRun     bool                `json:"dry_run"`
+	Migrations []*migrationPayload `json:"migrations"`
+}
+
+// migrateArgs contains the parsed args of the migrate command
+type migrateArgs struct {
+	cmd    string
+	steps  int
+	dryRun bool
+}
+
+// migrate runs the migrate command
+func migrate(connect connectFunc, out *output, args []string) error {
+	parsed, err := parseMigrateArgs(args)
+	if err != nil {
+		return err
+	}
+
+	appCtx, err := connect()
+	if err != nil {
+		return err
+	}
+
+	runner, err := migration.NewRunner(appCtx.DB, api.Migrations())
+	if err != nil {
+		return err
+	}
+
+	var done []*migration.Migration
+	switch parsed.cmd {
+	case "status":
+		return migrateStatus(runner, out)
+	case "up":
+		done, err = runner.Up(parsed.steps, parsed.dryRun)
+	case "down":
+		done, err = runner.Down(parsed.steps, parsed.dryRun)
+	}
+
+	// The migrations that succeeded are printed even if one failed
+	printMigrations(out, parsed.cmd, done, parsed.dryRun)
+	return err
+}
+
+// parseMigrateArgs parses the args of the migrate command. A usage error is
+// returned if they are invalid
+func parseMigrateArgs(args []string) (*migrateArgs, error) {
+	fs := newFlagSet("migrate")
+	if err := parseCommandFlags(fs, migrateUsage, args); err != nil {
+		return nil, err
+	}
+	args = fs.Args()
+
+	if len(args) == 0 {
+		return nil, newUsageError("missing command, usage: api %s", migrateUsage)
+	}
+
+	parsed := &migrateArgs{cmd: args[0]}
+	if parsed.cmd == "dry-run" {
+		if len(args) < 2 {
+			return nil, newUsageError("missing command, usage: api %s", migrateUsage)
+		}
+		parsed.dryRun = true
+		args = args[1:]
+		parsed.cmd = args[0]
+	}
+
+	var err error
+	switch parsed.cmd {
+	case "status":
+		if parsed.dryRun || len(args) > 1 {
+			return nil, newUsageError("status does not take any arguments")
+		}
+	case "up":
+		parsed.steps, err = parseSteps(args[1:], 0)
+	case "down":
+		parsed.steps, err = parseSteps(args[1:], 1)
+	default:
+		return nil, newUsageError("unknown command [%s], usage: api %s", parsed.cmd, migrateUsage)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	return parsed, nil
+}
+
+// migrateStatus prints the state of all the migrations
+func migrateStatus(runner *migration.Runner, out *output) error {
+	list, err := runner.Status()
+	if err != nil {
+		return err
+	}
+
+	pld := make([]*migrationPayload, len(list))
+	for i, s := range list {
+		pld[i] = newMigrationPayload(s.Migration)
+		if s.IsApplied() {
+			appliedAt := s.AppliedAt
+			pld[i].AppliedAt = &appliedAt
+		}
+	}
+
+	out.Print(pld, func(w io.Writer) {
+		if len(list) == 0 {
+			fmt.Fprintln(w, "no migrations")
+			return
+		}
+
+		for _, s := range list {
+			state := "pending"
+			if s.IsApplied() {
+				state = "applied " + s.AppliedAt.UTC().Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(w, "%-28s %s\n", state, s.Migration)
+		}
+	})
+	return nil
+}
+
+// printMigrations prints the migrations that have been ran
+func printMigrations(out *output, cmd string, list []*migration.Migration, dryRun bool) {
+	pld := &migrationsPayload{
+		DryRun:     dryRun,
+		Migrations: make([]*migrationPayload, len(list)),
+	}
+	for i, m := range list {
+		pld.Migrations[i] = newMigrationPayload(m)
+	}
+
+	out.Print(pld, func(w io.Writer) {
+		verb := "applied"
+		if cmd == "down" {
+			verb = "reverted"
+		}
+		if dryRun {
+			verb = "would be " + verb
+		}
+
+		if len(list) == 0 {
+			fmt.Fprintln(w, "nothing to do")
+			return
+		}
+
+		for _, m := range list {
+			fmt.Fprintf(w, "%s: %s\n", verb, m)
+		}
+	})
+}
+
+// newMigrationPayload turns a Migration into an object that can be
+// printed as JSON
+func newMigrationPayload(m *migration.Migration) *migrationPayload {
+	return &migrationPayload{
+		Version:     m.Version,
+		Description: m.Description,
+	}
+}
+
+// parseSteps returns the number of migrations to run, or def if args
+// is empty
+func parseSteps(args []string, def int) (int, error) {
+	if len(args) == 0 {
+		return def, nil
+	}
+
+	if len(args) > 1 {
+		return 0, newUsageError("too many arguments")
+	}
+
+	steps, err := strconv.Atoi(args[0])
+	if err != nil || steps < 1 {
+		return 0, newUsageError("invalid number of migrations [%s]", args[0])
+	}
+	return steps, nil
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSteps(t *testing.T) {
+	testCases := []struct {
+		description string
+		args        []string
+		def         int
+		steps       int
+		valid       bool
+	}{
+		{"No args", nil, 1, 1, true},
+		{"No args with 0 as default", []string{}, 0, 0, true},
+		{"Number", []string{"3"}, 1, 3, true},
+		{"Zero", []string{"0"}, 1, 0, false},
+		{"Negative", []string{"-2"}, 1, 0, false},
+		{"Not a number", []string{"all"}, 1, 0, false},
+		{"Too many args", []string{"1", "2"}, 1, 0, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			steps, err := parseSteps(tc.args, tc.def)
+			if !tc.valid {
+				assert.Equal(t, exitUsage, exitCode(err))
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.steps, steps)
+		})
+	}
+}
+
+func TestParseMigrateArgs(t *testing.T) {
+	testCases := []struct {
+		description string
+		args        []string
+		expected    *migrateArgs
+		err         error
+	}{
+		{"Status", []string{"status"}, &migrateArgs{cmd: "status"}, nil},
+		{"Up", []string{"up"}, &migrateArgs{cmd: "up", steps: 0}, nil},
+		{"Up with steps", []string{"up", "2"}, &migrateArgs{cmd: "up", steps: 2}, nil},
+		{"Down", []string{"down"}, &migrateArgs{cmd: "down", steps: 1}, nil},
+		{"Down with steps", []string{"down", "3"}, &migrateArgs{cmd: "down", steps: 3}, nil},
+		{"Dry-run up", []string{"dry-run", "up"}, &migrateArgs{cmd: "up", dryRun: true}, nil},
+		{"Dry-run down with steps", []string{"dry-run", "down", "2"}, &migrateArgs{cmd: "down", steps: 2, dryRun: true}, nil},
+		{"Help", []string{"-h"}, nil, errHelp},
+		{"No command", nil, nil, newUsageError("")},
+		{"Dry-run without command", []string{"dry-run"}, nil, newUsageError("")},
+		{"Dry-run status", []string{"dry-run", "status"}, nil, newUsageError("")},
+		{"Dry-run twice", []string{"dry-run", "dry-run", "up"}, nil, newUsageError("")},
+		{"Status with args", []string{"status", "1"}, nil, newUsageError("")},
+		{"Unknown command", []string{"redo"}, nil, newUsageError("")},
+		{"Invalid steps", []string{"down", "0"}, nil, newUsageError("")},
+		{"Unknown flag", []string{"-force", "up"}, nil, newUsageError("")},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			parsed, err := parseMigrateArgs(tc.args)
+
+			switch {
+			case tc.err == errHelp:
+				assert.Equal(t, errHelp, err)
+			case tc.err != nil:
+				assert.Equal(t, exitUsage, exitCode(err), "expected a usage error, got %v", err)
+			default:
+				if assert.NoError(t, err) {
+					assert.Equal(t, tc.expected, parsed)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/Nivl/api.melvin.la/api/app"
+	"github.com/Nivl/api.melvin.la/api/components/api"
+	"github.com/Nivl/api.melvin.la/api/migration"
+)
+
+// serve runs the HTTP server until SIGINT or SIGTERM is received
+func serve(connect connectFunc, out *output, args []string) error {
+	fs := newFlagSet("serve")
+	if err := parseCommandFlags(fs, "serve", args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return newUsageError("serve does not take any arguments")
+	}
+
+	appCtx, err := connect()
+	if err != nil {
+		return err
+	}
+
+	warnPendingMigrations(appCtx)
+	if err := api.EnsureIndexes(appCtx); err != nil {
+		return fmt.Errorf("could not create the indexes: %s", err.Error())
+	}
+
+	params := appCtx.Params
+	log := appCtx.Logger
+	server := &http.Server{
+		Addr:         ":" + params.Port,
+		Handler:      api.GetRouter(appCtx),
+		ReadTimeout:  params.ReadTimeout,
+		WriteTimeout: params.WriteTimeout,
+		IdleTimeout:  params.IdleTimeout,
+	}
+
+	// We stop accepting new requests on SIGINT and SIGTERM, and wait for the
+	// current ones to complete
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
+
+	serverErr := make(chan error, 1)
+	go func() {
+		log.Infof("listening on %s", server.Addr)
+		serverErr <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serverErr:
+		// ListenAndServe only returns when it fails to start
+		log.Errorf("could not start the server: %s", err.Error())
+		return err
+	case sig := <-stop:
+		log.Infof("received %s, shutting down", sig)
+	}
+
+	// The requests and the logs share the same deadline, so the whole
+	// shutdown fits in ShutdownTimeout. Destroy() then has nothing left to
+	// flush, and reports the error if the logs could not be flushed in time
+	deadline := time.Now().Add(params.ShutdownTimeout)
+	defer func() {
+		log.CloseTimeout(time.Until(deadline))
+	}()
+
+	ctx, cancel := context.WithDeadline(context.Background(), deadline)
+	defer cancel()
+
+	if err := server.Shutdown(ctx); err != nil {
+		log.Errorf("could not gracefully shut down the server: %s", err.Error())
+		return err
+	}
+	log.Info("server stopped")
+	return nil
+}
+
+// warnPendingMigrations logs a warning if some migrations have not been
+// applied, since the indexes may not be creatable without them
+func warnPendingMigrations(appCtx *app.Context) {
+	log := appCtx.Logger
+	runner, err := migration.NewRunner(appCtx.DB, api.Migrations())
+	if err != nil {
+		log.Errorf("invalid migrations: %s", err.Error())
+		return
+	}
+
+	pending, err := runner.Pending()
+	if err != nil {
+		log.Errorf("could not check the migrations: %s", err.Error())
+		return
+	}
+
+	if len(pending) > 0 {
+		log.Warnf("%d migrations are pending, run \"api migrate up\" to apply them", len(pending))
+	}
+}
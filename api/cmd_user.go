@@ -0,0 +1,213 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/Nivl/api.melvin.la/api/components/users"
+)
+
+const userUsage = `user <command>
+
+commands:
+  create -email EMAIL [-name NAME] [-role ROLE]... [-password PASSWORD]
+  reset-password -email EMAIL [-password PASSWORD]
+  grant -email EMAIL ROLE...
+
+The password is read from stdin when -password is not provided`
+
+// resetPasswordPayload represents the result of reset-password in the JSON
+// output
+type resetPasswordPayload struct {
+	User            *users.Exportable `json:"user"`
+	DeletedSessions int               `json:"deleted_sessions"`
+}
+
+// rolesFlag is a flag that can be repeated to provide several roles
+type rolesFlag []string
+
+// String implements flag.Value
+func (f *rolesFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+// Set implements flag.Value
+func (f *rolesFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+// user runs the user command
+func user(connect connectFunc, out *output, args []string) error {
+	if len(args) == 0 {
+		return newUsageError("missing command, usage: api %s", userUsage)
+	}
+
+	switch args[0] {
+	case "create":
+		return userCreate(connect, out, args[1:])
+	case "reset-password":
+		return userResetPassword(connect, out, args[1:])
+	case "grant":
+		return userGrant(connect, out, args[1:])
+	case "-h", "-help", "--help":
+		fmt.Fprintf(os.Stdout, "usage: api %s\n", userUsage)
+		return errHelp
+	}
+
+	return newUsageError("unknown command [%s], usage: api %s", args[0], userUsage)
+}
+
+// userCreate creates a new user
+func userCreate(connect connectFunc, out *output, args []string) error {
+	var roles rolesFlag
+	fs := newFlagSet("create")
+	email := fs.String("email", "", "email of the user (required)")
+	name := fs.String("name", "", "name of the user")
+	password := fs.String("password", "", "password of the user, read from stdin if empty")
+	fs.Var(&roles, "role", "role of the user, can be repeated")
+	if err := parseUserFlags(fs, "user create", args); err != nil {
+		return err
+	}
+
+	u := &users.User{
+		Name:  *name,
+		Email: *email,
+		Roles: roles,
+	}
+
+	if err := setPassword(u, *password); err != nil {
+		return err
+	}
+
+	appCtx, err := connect()
+	if err != nil {
+		return err
+	}
+
+	if err := u.Create(appCtx.DB); err != nil {
+		return err
+	}
+
+	printUser(out, u, "user created")
+	return nil
+}
+
+// userResetPassword changes the password of a user, and logs them out
+func userResetPassword(connect connectFunc, out *output, args []string) error {
+	fs := newFlagSet("reset-password")
+	email := fs.String("email", "", "email of the user (required)")
+	password := fs.String("password", "", "new password of the user, read from stdin if empty")
+	if err := parseUserFlags(fs, "user reset-password", args); err != nil {
+		return err
+	}
+
+	appCtx, err := connect()
+	if err != nil {
+		return err
+	}
+
+	u, err := users.GetByEmail(appCtx.DB, *email)
+	if err != nil {
+		return err
+	}
+
+	if err := setPassword(u, *password); err != nil {
+		return err
+	}
+
+	if err := u.Update(appCtx.DB); err != nil {
+		return err
+	}
+
+	// The sessions created with the old password should not be usable anymore
+	deleted, err := users.DeleteSessions(appCtx.DB, u)
+	if err != nil {
+		return err
+	}
+
+	pld := &resetPasswordPayload{
+		User:            users.NewPayloadFromModel(u),
+		DeletedSessions: deleted,
+	}
+	out.Print(pld, func(w io.Writer) {
+		fmt.Fprintf(w, "password of %s changed, %d sessions deleted\n", u.Email, deleted)
+	})
+	return nil
+}
+
+// userGrant adds roles to a user
+func userGrant(connect connectFunc, out *output, args []string) error {
+	fs := newFlagSet("grant")
+	email := fs.String("email", "", "email of the user (required)")
+	if err := parseUserFlags(fs, "user grant", args); err != nil {
+		return err
+	}
+
+	if fs.NArg() == 0 {
+		return newUsageError("no roles to grant")
+	}
+
+	appCtx, err := connect()
+	if err != nil {
+		return err
+	}
+
+	u, err := users.GetByEmail(appCtx.DB, *email)
+	if err != nil {
+		return err
+	}
+
+	if err := u.Grant(fs.Args()...); err != nil {
+		return err
+	}
+
+	if err := u.Update(appCtx.DB); err != nil {
+		return err
+	}
+
+	printUser(out, u, "roles granted")
+	return nil
+}
+
+// parseUserFlags parses the flags of a user command, which all require an
+// email
+func parseUserFlags(fs *flag.FlagSet, usage string, args []string) error {
+	if err := parseCommandFlags(fs, usage, args); err != nil {
+		return err
+	}
+
+	if fs.Lookup("email").Value.String() == "" {
+		return newUsageError("-email is required")
+	}
+	return nil
+}
+
+// setPassword sets the password of the user, reading it from stdin if
+// password is empty
+func setPassword(u *users.User, password string) error {
+	if password == "" {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && err != io.EOF {
+			return fmt.Errorf("could not read the password: %s", err.Error())
+		}
+		password = strings.TrimRight(line, "\r\n")
+	}
+
+	if password == "" {
+		return newUsageError("the password cannot be empty")
+	}
+
+	return u.SetPassword(password)
+}
+
+// printUser prints a user, after a message in human mode
+func printUser(out *output, u *users.User, msg string) {
+	out.Print(users.NewPayloadFromModel(u), func(w io.Writer) {
+		fmt.Fprintf(w, "%s: %s <%s> (id: %s, roles: %s)\n", msg, u.Name, u.Email, u.ID.Hex(), strings.Join(u.Roles, ", "))
+	})
+}
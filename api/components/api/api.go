@@ -1,20 +1,47 @@
 package api
 
 import (
+	"github.com/Nivl/api.melvin.la/api/app"
 	"github.com/Nivl/api.melvin.la/api/components/blog"
+	"github.com/Nivl/api.melvin.la/api/components/status"
+	"github.com/Nivl/api.melvin.la/api/components/users"
+	"github.com/Nivl/api.melvin.la/api/migration"
+	"github.com/Nivl/api.melvin.la/api/router"
 	"github.com/gorilla/mux"
 )
 
-func EnsureIndexes() {
-	blog.EnsureIndexes()
+// EnsureIndexes sets the indexes of all the documents in the database of
+// the given app
+func EnsureIndexes(a *app.Context) error {
+	if err := blog.EnsureIndexes(a.DB); err != nil {
+		return err
+	}
+
+	if err := users.EnsureIndexes(a.DB); err != nil {
+		return err
+	}
+
+	a.IndexesEnsured = true
+	return nil
 }
 
-func GetRouter() *mux.Router {
+// GetRouter returns the router of the API, handling the requests using the
+// given app. The given middlewares are ran on every endpoints
+func GetRouter(a *app.Context, middlewares ...router.Middleware) *mux.Router {
 	r := mux.NewRouter()
 	r.Host("api.melvin.la")
 	r.Host("api.melvin.loc")
-	blog.SetRoutes(r.PathPrefix("/blog").Subrouter())
+
+	g := router.NewGroup(r, a, middlewares...)
+	status.SetRoutes(g)
+	blog.SetRoutes(g.Group("/blog"))
+	users.SetRoutes(g.Group("/users"))
 	//router.NotFoundHandler = http.HandlerFunc(noRoutes)
 
 	return r
 }
+
+// Migrations returns all the migrations of the API
+func Migrations() []*migration.Migration {
+	return blog.Migrations()
+}
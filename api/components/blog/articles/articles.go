@@ -1,21 +1,32 @@
 package articles
 
-import (
-	"github.com/Nivl/api.melvin.la/api/app"
-	"gopkg.in/mgo.v2"
-)
+import "gopkg.in/mgo.v2"
+
+// indexes contains the indexes of the Articles document
+var indexes = []mgo.Index{
+	// Duplicated slugs are renamed by a migration since DropDups is not
+	// supported by Mongo anymore
+	mgo.Index{Key: []string{"slug"}, Unique: true, Background: true},
+
+	// The sorts and filters of List() rely on the following indexes. They
+	// used to be created on the unused "articles" collection, so on existing
+	// databases the list is only backed by them once the 20261016120000
+	// migration has been applied
+	mgo.Index{Key: []string{"-created_at"}, Background: true},
+	mgo.Index{Key: []string{"-published_at"}, Background: true},
+	mgo.Index{Key: []string{"title"}, Background: true},
+	mgo.Index{Key: []string{"tags"}, Background: true},
+	mgo.Index{Key: []string{"author_id"}, Background: true},
+}
 
 // EnsureIndexes sets the indexes for the Articles document
-func EnsureIndexes() {
-	indexes := []mgo.Index{
-		mgo.Index{Key: []string{"slug"}, Unique: true, DropDups: true, Background: true},
-		mgo.Index{Key: []string{"-created_at"}, Background: true},
-	}
-	doc := app.GetContext().DB.C("articles")
+func EnsureIndexes(db *mgo.Database) error {
+	doc := Query(db)
 
 	for _, index := range indexes {
 		if err := doc.EnsureIndex(index); err != nil {
-			panic(err)
+			return err
 		}
 	}
+	return nil
 }
@@ -1,8 +1,24 @@
 package articles_test
 
-import "github.com/Nivl/api.melvin.la/api/app"
+import (
+	"os"
+	"testing"
 
-func init() {
-	app.InitContex()
-	// defer app.GetContext().Destroy()
+	"github.com/Nivl/api.melvin.la/api/app"
+	"github.com/Nivl/api.melvin.la/api/app/testhelpers"
+)
+
+// testApp is the app used by the tests of the package
+var testApp *app.Context
+
+func TestMain(m *testing.M) {
+	var err error
+	testApp, err = testhelpers.NewApp()
+	if err != nil {
+		panic(err)
+	}
+
+	code := m.Run()
+	testApp.Destroy()
+	os.Exit(code)
 }
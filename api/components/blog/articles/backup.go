@@ -0,0 +1,98 @@
+package articles
+
+import (
+	"time"
+
+	"github.com/Nivl/api.melvin.la/api/apierror"
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// ImportResult contains the number of articles saved by Import()
+type ImportResult struct {
+	Created int `json:"created"`
+	Updated int `json:"updated"`
+}
+
+// Export returns all the articles of the given database, including the ones
+// in the trash, sorted by ID
+func Export(db *mgo.Database) ([]*Article, error) {
+	arts := []*Article{}
+	if err := Query(db).Find(nil).Sort("_id").All(&arts); err != nil {
+		return nil, apierror.NewServerError("%s", err.Error())
+	}
+	return arts, nil
+}
+
+// Import saves the given articles, usually coming from Export().
+// The articles having an ID are saved as they are, replacing the existing
+// article with the same ID. The other ones are created like new articles.
+// The import stops at the first failure, and the result contains the
+// articles that have been saved so far
+func Import(db *mgo.Database, arts []*Article) (*ImportResult, error) {
+	res := &ImportResult{}
+
+	for _, a := range arts {
+		if a == nil {
+			continue
+		}
+
+		if a.ID == "" {
+			if err := NewMongoStore(db).Create(a); err != nil {
+				return res, err
+			}
+			res.Created++
+			continue
+		}
+
+		updated, err := importArticle(db, a)
+		if err != nil {
+			return res, err
+		}
+
+		if updated {
+			res.Updated++
+		} else {
+			res.Created++
+		}
+	}
+
+	return res, nil
+}
+
+// importArticle inserts or replaces the given article, and reports whether
+// an existing article has been replaced
+func importArticle(db *mgo.Database, a *Article) (bool, error) {
+	if !a.ID.Valid() {
+		return false, apierror.NewInvalidParam("id", "id [%s] is not a valid ObjectId", string(a.ID))
+	}
+
+	if err := a.setSlug(); err != nil {
+		return false, err
+	}
+
+	// Like Update(), the slug is kept as it is
+	query := bson.M{"slug": a.Slug, "_id": bson.M{"$ne": a.ID}}
+	count, err := Query(db).Find(query).Count()
+	if err != nil {
+		return false, apierror.NewServerError("%s", err.Error())
+	}
+	if count > 0 {
+		return false, apierror.NewConflict("slug [%s] of article [%s] already exists", a.Slug, a.ID.Hex())
+	}
+
+	if a.CreatedAt.IsZero() {
+		a.CreatedAt = time.Now()
+	}
+	a.setPublicationDate()
+
+	info, err := Query(db).UpsertId(a.ID, a)
+	if err != nil {
+		if mgo.IsDup(err) {
+			return false, apierror.NewConflict("slug [%s] of article [%s] already exists", a.Slug, a.ID.Hex())
+		}
+		return false, apierror.NewServerError("%s", err.Error())
+	}
+
+	return info.UpsertedId == nil, nil
+}
@@ -0,0 +1,116 @@
+package articles_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Nivl/api.melvin.la/api/apierror"
+	"github.com/Nivl/api.melvin.la/api/app/testhelpers"
+	"github.com/Nivl/api.melvin.la/api/components/blog/articles"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/mgo.v2/bson"
+)
+
+func TestExportImport(t *testing.T) {
+	published := articles.NewTestArticle(t, testApp.DB, nil)
+	testhelpers.SaveModel(t, testApp.DB, published)
+
+	trashed := articles.NewTestArticle(t, testApp.DB, &articles.Article{IsDeleted: true, DeletedAt: time.Now()})
+	testhelpers.SaveModel(t, testApp.DB, trashed)
+
+	defer testhelpers.PurgeModels(t)
+
+	exported, err := articles.Export(testApp.DB)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The backups are stored as JSON
+	backup := []*articles.Article{}
+	for _, a := range exported {
+		if a.ID == published.ID || a.ID == trashed.ID {
+			backup = append(backup, a)
+		}
+	}
+	if !assert.Len(t, backup, 2) {
+		return
+	}
+
+	data, err := json.Marshal(backup)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	backup = []*articles.Article{}
+	if err := json.Unmarshal(data, &backup); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := published.FullyDelete(testApp.DB); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := articles.Import(testApp.DB, backup)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 1, res.Created)
+	assert.Equal(t, 1, res.Updated)
+
+	restored, err := articles.NewMongoStore(testApp.DB).Get(published.ID.Hex(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, published.Slug, restored.Slug)
+	assert.Equal(t, published.CreatedAt.Unix(), restored.CreatedAt.Unix())
+
+	restored, err = articles.NewMongoStore(testApp.DB).Get(trashed.ID.Hex(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, restored.IsDeleted)
+}
+
+func TestImportInvalid(t *testing.T) {
+	a := articles.NewTestArticle(t, testApp.DB, nil)
+	testhelpers.SaveModel(t, testApp.DB, a)
+
+	defer testhelpers.PurgeModels(t)
+
+	testCases := []struct {
+		description string
+		article     *articles.Article
+		code        int
+	}{
+		{
+			"Used slug",
+			&articles.Article{ID: bson.NewObjectId(), Title: "title", Slug: a.Slug},
+			http.StatusConflict,
+		},
+		{
+			"Reserved slug",
+			&articles.Article{ID: bson.NewObjectId(), Title: "title", Slug: "trash"},
+			http.StatusBadRequest,
+		},
+		{
+			"Invalid ID",
+			&articles.Article{ID: bson.ObjectId("nope"), Title: "title"},
+			http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			res, err := articles.Import(testApp.DB, []*articles.Article{tc.article})
+			if assert.Error(t, err) {
+				apiErr, ok := err.(apierror.Error)
+				if assert.True(t, ok) {
+					assert.Equal(t, tc.code, apiErr.Code())
+				}
+			}
+			assert.Equal(t, 0, res.Created+res.Updated)
+		})
+	}
+}
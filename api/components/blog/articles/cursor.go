@@ -0,0 +1,54 @@
+package articles
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	"github.com/Nivl/api.melvin.la/api/apierror"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// Cursor represents the position of the last article of a page. It is
+// returned to the clients as an opaque string
+type Cursor struct {
+	// Sort contains the sort used to generate the page, since a cursor
+	// cannot be used with a different sort
+	Sort string `json:"s"`
+
+	// ID contains the ID of the last article, to break ties
+	ID bson.ObjectId `json:"i"`
+
+	// Time contains the value of the sorted field when it's a date
+	Time time.Time `json:"t,omitempty"`
+
+	// Text contains the value of the sorted field when it's a string
+	Text string `json:"v,omitempty"`
+}
+
+// Encode returns the opaque representation of the cursor
+func (c *Cursor) Encode() string {
+	dump, err := json.Marshal(c)
+	if err != nil {
+		// Cannot happen since all the fields can be marshalled
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(dump)
+}
+
+// DecodeCursor parses an opaque cursor
+func DecodeCursor(cursor string) (*Cursor, error) {
+	invalid := apierror.NewInvalidParam("cursor", "value [%s] for parameter [cursor] is invalid", cursor)
+
+	dump, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, invalid
+	}
+
+	c := &Cursor{}
+	if err := json.Unmarshal(dump, c); err != nil || !c.ID.Valid() {
+		return nil, invalid
+	}
+
+	return c, nil
+}
@@ -3,13 +3,16 @@ package articles
 import (
 	"github.com/Nivl/api.melvin.la/api/apierror"
 	"github.com/Nivl/api.melvin.la/api/router"
+	"gopkg.in/mgo.v2/bson"
 )
 
 type HandlerAddParams struct {
-	Title       string `from:"form" json:"title,omitempty" params:"required,trim"`
-	Subtitle    string `from:"form" json:"subtitle,omitempty"`
-	Description string `from:"form" json:"description,omitempty"`
-	Content     string `from:"form" json:"content,omitempty"`
+	Title       string   `from:"form" json:"title,omitempty" params:"required,trim,max_len=255"`
+	Subtitle    string   `from:"form" json:"subtitle,omitempty" params:"max_len=255"`
+	Slug        string   `from:"form" json:"slug,omitempty" params:"trim,slug,max_len=255"`
+	Description string   `from:"form" json:"description,omitempty"`
+	Content     string   `from:"form" json:"content,omitempty"`
+	Tags        []string `from:"form" json:"tags,omitempty"`
 }
 
 // HandlerAdd represents an API handler to add a new article
@@ -17,20 +20,25 @@ func HandlerAdd(req *router.Request) {
 	params, ok := req.Params.(*HandlerAddParams)
 	if !ok {
 		req.Error(apierror.NewServerError("Couldn't cast params"))
+		return
 	}
 
 	a := &Article{
 		Title:       params.Title,
+		Slug:        params.Slug,
 		Subtitle:    params.Subtitle,
 		Content:     params.Content,
 		Description: params.Description,
 		IsDeleted:   false,
 		IsPublished: false,
+		AuthorID:    bson.ObjectIdHex(req.User.UserID()),
 	}
+	a.SetTags(params.Tags)
 
-	if err := a.Save(); err != nil {
+	if err := store(req).Create(a); err != nil {
 		req.Error(err)
+		return
 	}
 
-	req.Created(a)
+	req.Created(NewPayloadFromModel(a))
 }
@@ -1,56 +1,189 @@
 package articles_test
 
 import (
+	"bytes"
 	"encoding/json"
+	"io"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
 
 	"github.com/Nivl/api.melvin.la/api/app/testhelpers"
 	"github.com/Nivl/api.melvin.la/api/components/blog/articles"
+	"github.com/Nivl/api.melvin.la/api/components/users"
+	"github.com/Nivl/api.melvin.la/api/router"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestHandlerAdd(t *testing.T) {
+	u, s := users.NewTestAuth(t, testApp.DB, users.RoleAuthor)
+	testhelpers.SaveModel(t, testApp.DB, u)
+	testhelpers.SaveModel(t, testApp.DB, s)
+
+	noPermsUser, noPerms := users.NewTestAuth(t, testApp.DB)
+	testhelpers.SaveModel(t, testApp.DB, noPermsUser)
+	testhelpers.SaveModel(t, testApp.DB, noPerms)
+
+	scopedKey := users.NewTestAPIKey(t, testApp.DB, u, users.PermissionArticlesWrite)
+	testhelpers.SaveModel(t, testApp.DB, scopedKey)
+
+	unscopedKey := users.NewTestAPIKey(t, testApp.DB, u)
+	testhelpers.SaveModel(t, testApp.DB, unscopedKey)
+	defer testhelpers.PurgeModels(t)
+
+	// The users are in the database, but the articles are kept in memory
+	store := articles.NewMemoryStore()
+
 	tests := []struct {
 		description string
 		params      *articles.HandlerAddParams
+		token       string
 		code        int
 	}{
-		{"No Title", &articles.HandlerAddParams{}, http.StatusBadRequest},
-		{"Title filled with spaces", &articles.HandlerAddParams{Title: "       "}, http.StatusBadRequest},
-		{"As few params as possible", &articles.HandlerAddParams{Title: "My Super Article"}, http.StatusCreated},
-		{"Duplicate title", &articles.HandlerAddParams{Title: "My Super Article"}, http.StatusCreated},
+		{"No auth", &articles.HandlerAddParams{Title: "My Super Article"}, "", http.StatusUnauthorized},
+		{"No permission", &articles.HandlerAddParams{Title: "My Super Article"}, noPerms.Token, http.StatusForbidden},
+		{"API key without scope", &articles.HandlerAddParams{Title: "My Super Article"}, unscopedKey.Key, http.StatusForbidden},
+		{"API key with scope", &articles.HandlerAddParams{Title: "My Super Article"}, scopedKey.Key, http.StatusCreated},
+		{"Invalid token", &articles.HandlerAddParams{Title: "My Super Article"}, "invalid", http.StatusUnauthorized},
+		{"No Title", &articles.HandlerAddParams{}, s.Token, http.StatusBadRequest},
+		{"Title filled with spaces", &articles.HandlerAddParams{Title: "       "}, s.Token, http.StatusBadRequest},
+		{"Title too long", &articles.HandlerAddParams{Title: strings.Repeat("a", 256)}, s.Token, http.StatusBadRequest},
+		{"Invalid slug", &articles.HandlerAddParams{Title: "My Super Article", Slug: "Not a slug"}, s.Token, http.StatusBadRequest},
+		{"Custom slug", &articles.HandlerAddParams{Title: "My Super Article", Slug: "my-custom-slug"}, s.Token, http.StatusCreated},
+		{"As few params as possible", &articles.HandlerAddParams{Title: "My Super Article"}, s.Token, http.StatusCreated},
+		{"Duplicate title", &articles.HandlerAddParams{Title: "My Super Article"}, s.Token, http.StatusCreated},
+		{"Duplicate slug", &articles.HandlerAddParams{Title: "Other Article", Slug: "my-custom-slug"}, s.Token, http.StatusCreated},
 	}
 
 	for _, tc := range tests {
 		t.Run(tc.description, func(t *testing.T) {
-			rec := callHandlerAdd(t, tc.params)
+			rec := callHandlerAdd(t, store, tc.params, tc.token)
 			assert.Equal(t, tc.code, rec.Code)
 
 			if rec.Code == http.StatusCreated {
-				var a articles.Article
-				if err := json.NewDecoder(rec.Body).Decode(&a); err != nil {
+				var pld articles.Exportable
+				if err := json.NewDecoder(rec.Body).Decode(&pld); err != nil {
 					t.Fatal(err)
 				}
 
-				assert.NotEmpty(t, a.ID)
-				assert.NotEmpty(t, a.Slug)
-				assert.Equal(t, tc.params.Title, a.Title)
-				if err := a.FullyDelete(); err != nil {
-					t.Fatal(err)
+				assert.NotEmpty(t, pld.ID)
+				assert.NotEmpty(t, pld.Slug)
+				assert.NotEmpty(t, pld.CreatedAt)
+				assert.Equal(t, tc.params.Title, pld.Title)
+				assert.Equal(t, u.ID.Hex(), pld.AuthorID)
+				assert.Equal(t, []string{}, pld.Tags)
+
+				saved, err := store.Get(pld.ID, nil)
+				if assert.NoError(t, err) {
+					assert.Equal(t, pld.Slug, saved.Slug)
 				}
 			}
 		})
 	}
 }
 
-func callHandlerAdd(t *testing.T, params *articles.HandlerAddParams) *httptest.ResponseRecorder {
+func TestHandlerAddWithForm(t *testing.T) {
+	u, s := users.NewTestAuth(t, testApp.DB, users.RoleAuthor)
+	testhelpers.SaveModel(t, testApp.DB, u)
+	testhelpers.SaveModel(t, testApp.DB, s)
+	defer testhelpers.PurgeModels(t)
+
+	store := articles.NewMemoryStore()
+	form := url.Values{"title": {"My Form Article"}, "tags": {"go", "html"}}
+
+	var multipartBody bytes.Buffer
+	writer := multipart.NewWriter(&multipartBody)
+	for name, values := range form {
+		for _, value := range values {
+			if err := writer.WriteField(name, value); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		description string
+		body        io.Reader
+		contentType string
+	}{
+		{"urlencoded", strings.NewReader(form.Encode()), "application/x-www-form-urlencoded"},
+		{"multipart", &multipartBody, writer.FormDataContentType()},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			ri := &testhelpers.RequestInfo{
+				Test:        t,
+				App:         testApp,
+				Endpoint:    articles.Endpoints[articles.EndpointAdd],
+				URI:         "/blog/articles/",
+				Token:       s.Token,
+				Body:        tc.body,
+				ContentType: tc.contentType,
+				Middlewares: []router.Middleware{articles.UseStore(store)},
+			}
+
+			rec := testhelpers.NewRequest(ri)
+			if !assert.Equal(t, http.StatusCreated, rec.Code) {
+				return
+			}
+
+			var pld articles.Exportable
+			if err := json.NewDecoder(rec.Body).Decode(&pld); err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, "My Form Article", pld.Title)
+			assert.Equal(t, []string{"go", "html"}, pld.Tags)
+		})
+	}
+}
+
+// TestHandlerAddResponse checks that the response only contains the public
+// fields of the article
+func TestHandlerAddResponse(t *testing.T) {
+	u, s := users.NewTestAuth(t, testApp.DB, users.RoleAuthor)
+	testhelpers.SaveModel(t, testApp.DB, u)
+	testhelpers.SaveModel(t, testApp.DB, s)
+	defer testhelpers.PurgeModels(t)
+
+	params := &articles.HandlerAddParams{Title: "My Super Article", Tags: []string{"go"}}
+	rec := callHandlerAdd(t, articles.NewMemoryStore(), params, s.Token)
+	if !assert.Equal(t, http.StatusCreated, rec.Code) {
+		return
+	}
+
+	var body map[string]interface{}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"id", "title", "content", "slug", "subtitle", "description", "tags", "author_id", "created_at", "is_published"}
+	for _, field := range expected {
+		assert.Contains(t, body, field)
+	}
+	assert.Equal(t, len(expected), len(body), "unexpected fields in %v", body)
+
+	assert.Equal(t, "my-super-article", body["slug"])
+	assert.Equal(t, u.ID.Hex(), body["author_id"])
+	assert.Equal(t, false, body["is_published"])
+}
+
+func callHandlerAdd(t *testing.T, store articles.ArticleStore, params *articles.HandlerAddParams, token string) *httptest.ResponseRecorder {
 	ri := &testhelpers.RequestInfo{
-		Test:     t,
-		Endpoint: articles.Endpoints[articles.EndpointAdd],
-		URI:      "/blog/articles/",
-		Params:   params,
+		Test:        t,
+		App:         testApp,
+		Endpoint:    articles.Endpoints[articles.EndpointAdd],
+		URI:         "/blog/articles/",
+		Params:      params,
+		Token:       token,
+		Middlewares: []router.Middleware{articles.UseStore(store)},
 	}
 
 	return testhelpers.NewRequest(ri)
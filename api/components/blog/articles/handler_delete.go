@@ -1,31 +1,34 @@
 package articles
 
-import "github.com/Nivl/api.melvin.la/api/router"
+import (
+	"github.com/Nivl/api.melvin.la/api/apierror"
+	"github.com/Nivl/api.melvin.la/api/router"
+)
 
-// HandlerDelete represents a API handler to delete an article
+// HandlerDeleteParams represents the params accepted by HandlerDelete
+type HandlerDeleteParams struct {
+	ID string `from:"url" json:"id" params:"required,trim"`
+}
+
+// HandlerDelete represents a API handler to move an article to the trash
 func HandlerDelete(req *router.Request) {
-	//appCtx := app.GetContext()
-	//doc := appCtx.DB.C("article")
-	//article := Article{
-	//	ID:        bson.NewObjectId(),
-	//	CreatedAt: time.Now(),
-	//}
-	//
-	//if err := gin.Bind(&article); err != nil {
-	//	req.BadRequest(err)
-	//	return
-	//}
-	//
-	//if err := doc.Insert(article); err != nil {
-	//	if mgo.IsDup(err) {
-	//		req.Conflict("The slug %s already exists in the database", article.Slug)
-	//		return
-	//	}
-	//
-	//	req.ServerError(err)
-	//	return
-	//}
+	params, ok := req.Params.(*HandlerDeleteParams)
+	if !ok {
+		req.Error(apierror.NewServerError("Couldn't cast params"))
+		return
+	}
+
+	s := store(req)
+	a, err := s.Get(params.ID, defaultSearch)
+	if err != nil {
+		req.Error(err)
+		return
+	}
+
+	if err := s.Trash(a); err != nil {
+		req.Error(err)
+		return
+	}
 
-	//httpResponse.Ok(gin, httpResponse.Resource{article})
 	req.NoContent()
 }
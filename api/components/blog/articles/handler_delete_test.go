@@ -0,0 +1,71 @@
+package articles_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Nivl/api.melvin.la/api/app/testhelpers"
+	"github.com/Nivl/api.melvin.la/api/components/blog/articles"
+	"github.com/Nivl/api.melvin.la/api/components/users"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/mgo.v2/bson"
+)
+
+func TestHandlerDelete(t *testing.T) {
+	u, s := users.NewTestAuth(t, testApp.DB, users.RoleAuthor)
+	testhelpers.SaveModel(t, testApp.DB, u)
+	testhelpers.SaveModel(t, testApp.DB, s)
+
+	noPermsUser, noPerms := users.NewTestAuth(t, testApp.DB)
+	testhelpers.SaveModel(t, testApp.DB, noPermsUser)
+	testhelpers.SaveModel(t, testApp.DB, noPerms)
+
+	a := articles.NewTestArticle(t, testApp.DB, nil)
+	testhelpers.SaveModel(t, testApp.DB, a)
+
+	trashed := articles.NewTestArticle(t, testApp.DB, &articles.Article{IsDeleted: true})
+	testhelpers.SaveModel(t, testApp.DB, trashed)
+
+	defer testhelpers.PurgeModels(t)
+
+	tests := []struct {
+		description string
+		id          string
+		token       string
+		code        int
+	}{
+		{"No auth", a.ID.Hex(), "", http.StatusUnauthorized},
+		{"No permission", a.ID.Hex(), noPerms.Token, http.StatusForbidden},
+		{"Unknown article", bson.NewObjectId().Hex(), s.Token, http.StatusNotFound},
+		{"Already in the trash", trashed.ID.Hex(), s.Token, http.StatusNotFound},
+		{"Valid article", a.ID.Hex(), s.Token, http.StatusNoContent},
+		{"Deleting twice", a.ID.Hex(), s.Token, http.StatusNotFound},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			rec := callHandlerDelete(t, tc.id, tc.token)
+			assert.Equal(t, tc.code, rec.Code)
+
+			if rec.Code == http.StatusNoContent {
+				deleted, err := articles.NewMongoStore(testApp.DB).Get(tc.id, nil)
+				if assert.NoError(t, err) {
+					assert.True(t, deleted.IsDeleted)
+				}
+			}
+		})
+	}
+}
+
+func callHandlerDelete(t *testing.T, id string, token string) *httptest.ResponseRecorder {
+	ri := &testhelpers.RequestInfo{
+		Test:     t,
+		App:      testApp,
+		Endpoint: articles.Endpoints[articles.EndpointDelete],
+		URI:      "/blog/articles/" + id,
+		Token:    token,
+	}
+
+	return testhelpers.NewRequest(ri)
+}
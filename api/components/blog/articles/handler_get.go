@@ -1,23 +1,35 @@
 package articles
 
-import "github.com/Nivl/api.melvin.la/api/router"
+import (
+	"github.com/Nivl/api.melvin.la/api/apierror"
+	"github.com/Nivl/api.melvin.la/api/components/users"
+	"github.com/Nivl/api.melvin.la/api/router"
+)
+
+// HandlerGetParams represents the params accepted by HandlerGet
+type HandlerGetParams struct {
+	ID string `from:"url" json:"id" params:"required,trim"`
+}
 
 // HandlerGet represents a API handler to get a single article
 func HandlerGet(req *router.Request) {
-	// appCtx := app.GetContext()
-	// doc := appCtx.DB.C("article")
-	// article := article.Article{}
-	// id := bson.ObjectIdHex(mux.Vars(req.Request)["id"])
+	params, ok := req.Params.(*HandlerGetParams)
+	if !ok {
+		req.Error(apierror.NewServerError("Couldn't cast params"))
+		return
+	}
+
+	// Unpublished articles can only be seen by the users that can edit them
+	filters := publicSearch
+	if req.User != nil && req.User.HasPermission(users.PermissionArticlesWrite) {
+		filters = defaultSearch
+	}
 
-	// if err := doc.Find(bson.M{"_id": id}).One(&article); err != nil {
-	// 	if err == mgo.ErrNotFound {
-	// 		req.NotFound("Article %s not found", id)
-	// 		return
-	// 	}
-	// 	req.Error(NewSer)
-	// 	return
-	// }
+	a, err := store(req).Get(params.ID, filters)
+	if err != nil {
+		req.Error(err)
+		return
+	}
 
-	//httpResponse.Ok(gin, httpResponse.Resource{article})
-	req.NoContent()
+	req.Ok(NewPayloadFromModel(a))
 }
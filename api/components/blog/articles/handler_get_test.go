@@ -0,0 +1,79 @@
+package articles_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Nivl/api.melvin.la/api/app/testhelpers"
+	"github.com/Nivl/api.melvin.la/api/components/blog/articles"
+	"github.com/Nivl/api.melvin.la/api/components/users"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/mgo.v2/bson"
+)
+
+func TestHandlerGet(t *testing.T) {
+	published := articles.NewTestArticle(t, testApp.DB, nil)
+	testhelpers.SaveModel(t, testApp.DB, published)
+
+	unpublished := articles.NewTestArticle(t, testApp.DB, &articles.Article{IsPublished: false})
+	testhelpers.SaveModel(t, testApp.DB, unpublished)
+
+	deleted := articles.NewTestArticle(t, testApp.DB, &articles.Article{IsPublished: true, IsDeleted: true})
+	testhelpers.SaveModel(t, testApp.DB, deleted)
+
+	u, s := users.NewTestAuth(t, testApp.DB, users.RoleAuthor)
+	testhelpers.SaveModel(t, testApp.DB, u)
+	testhelpers.SaveModel(t, testApp.DB, s)
+
+	defer testhelpers.PurgeModels(t)
+
+	tests := []struct {
+		description string
+		id          string
+		token       string
+		code        int
+		article     *articles.Article
+	}{
+		{"Get by ID", published.ID.Hex(), "", http.StatusOK, published},
+		{"Get by slug", published.Slug, "", http.StatusOK, published},
+		{"Unknown ID", bson.NewObjectId().Hex(), "", http.StatusNotFound, nil},
+		{"Unknown slug", "this-slug-does-not-exist", "", http.StatusNotFound, nil},
+		{"Unpublished article", unpublished.ID.Hex(), "", http.StatusNotFound, nil},
+		{"Unpublished article as author", unpublished.ID.Hex(), s.Token, http.StatusOK, unpublished},
+		{"Deleted article", deleted.Slug, "", http.StatusNotFound, nil},
+		{"Deleted article as author", deleted.Slug, s.Token, http.StatusNotFound, nil},
+		{"Invalid token", published.Slug, "invalid", http.StatusUnauthorized, nil},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			rec := callHandlerGet(t, tc.id, tc.token)
+			assert.Equal(t, tc.code, rec.Code)
+
+			if rec.Code == http.StatusOK {
+				var a articles.Exportable
+				if err := json.NewDecoder(rec.Body).Decode(&a); err != nil {
+					t.Fatal(err)
+				}
+
+				assert.Equal(t, tc.article.ID.Hex(), a.ID)
+				assert.Equal(t, tc.article.Slug, a.Slug)
+				assert.Equal(t, tc.article.Title, a.Title)
+			}
+		})
+	}
+}
+
+func callHandlerGet(t *testing.T, id string, token string) *httptest.ResponseRecorder {
+	ri := &testhelpers.RequestInfo{
+		Test:     t,
+		App:      testApp,
+		Endpoint: articles.Endpoints[articles.EndpointGet],
+		URI:      "/blog/articles/" + id,
+		Token:    token,
+	}
+
+	return testhelpers.NewRequest(ri)
+}
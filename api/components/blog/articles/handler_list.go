@@ -1,17 +1,83 @@
 package articles
 
 import (
+	"time"
+
+	"github.com/Nivl/api.melvin.la/api/apierror"
+	"github.com/Nivl/api.melvin.la/api/components/users"
 	"github.com/Nivl/api.melvin.la/api/router"
+	"gopkg.in/mgo.v2/bson"
 )
 
+// HandlerListParams represents the params accepted by HandlerList
+type HandlerListParams struct {
+	Limit         int           `from:"query" json:"limit" default:"20" params:"min=1"`
+	Cursor        string        `from:"query" json:"cursor" params:"trim"`
+	Sort          string        `from:"query" json:"sort" default:"-created_at" params:"trim"`
+	Published     *bool         `from:"query" json:"published"`
+	Tag           string        `from:"query" json:"tag" params:"trim"`
+	Author        bson.ObjectId `from:"query" json:"author" params:"trim"`
+	CreatedAfter  time.Time     `from:"query" json:"created_after" params:"trim"`
+	CreatedBefore time.Time     `from:"query" json:"created_before" params:"trim"`
+}
+
+// ListPayload represents a page of articles that can be safely returned by
+// the API
+type ListPayload struct {
+	Data       []*Exportable `json:"data"`
+	NextCursor string        `json:"next_cursor"`
+	HasMore    bool          `json:"has_more"`
+}
+
 // HandlerList represents a API handler to get a list of articles
 func HandlerList(req *router.Request) {
-	arts := []*Article{}
+	params, ok := req.Params.(*HandlerListParams)
+	if !ok {
+		req.Error(apierror.NewServerError("Couldn't cast params"))
+		return
+	}
+
+	opts := &ListOptions{
+		Sort:  params.Sort,
+		Limit: params.Limit,
+		Filters: ListFilters{
+			Published:     params.Published,
+			Tag:           params.Tag,
+			AuthorID:      params.Author,
+			CreatedAfter:  params.CreatedAfter,
+			CreatedBefore: params.CreatedBefore,
+		},
+	}
 
-	if err := Query().Find(defaultSearch).Sort("-createdAt").All(&arts); err != nil {
+	// Unpublished articles can only be seen by the users that can edit them
+	if req.User == nil || !req.User.HasPermission(users.PermissionArticlesWrite) {
+		published := true
+		opts.Filters.Published = &published
+	}
+
+	if params.Cursor != "" {
+		cursor, err := DecodeCursor(params.Cursor)
+		if err != nil {
+			req.Error(err)
+			return
+		}
+		opts.Cursor = cursor
+	}
+
+	res, err := store(req).List(opts)
+	if err != nil {
 		req.Error(err)
 		return
 	}
 
-	req.Ok(NewPayloadFromModels(arts))
+	pld := &ListPayload{
+		Data:    NewPayloadFromModels(res.Articles),
+		HasMore: res.Next != nil,
+	}
+
+	if res.Next != nil {
+		pld.NextCursor = res.Next.Encode()
+	}
+
+	req.Ok(pld)
 }
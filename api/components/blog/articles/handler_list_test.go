@@ -4,52 +4,182 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"sort"
 	"testing"
 
+	"github.com/Nivl/api.melvin.la/api/apierror"
 	"github.com/Nivl/api.melvin.la/api/app/testhelpers"
 	"github.com/Nivl/api.melvin.la/api/components/blog/articles"
+	"github.com/Nivl/api.melvin.la/api/components/users"
+	"github.com/Nivl/api.melvin.la/api/router"
+	"github.com/dchest/uniuri"
 	"github.com/stretchr/testify/assert"
 )
 
 // ListTest tests the List handler
 func TestHandlerList(t *testing.T) {
+	// The articles are kept in memory, so the test only sees its own
+	store := articles.NewMemoryStore()
 	for i := 0; i < 10; i++ {
-		a := articles.NewTestArticle(t, nil)
-		testhelpers.SaveModel(t, a)
+		newMemoryArticle(t, store, i < 7)
 	}
+
+	u, s := users.NewTestAuth(t, testApp.DB, users.RoleAuthor)
+	testhelpers.SaveModel(t, testApp.DB, u)
+	testhelpers.SaveModel(t, testApp.DB, s)
+
 	defer testhelpers.PurgeModels(t)
 
 	tests := []struct {
 		description string
+		query       url.Values
+		token       string
 		countWanted int
 		code        int
 	}{
-		{"No params", 10, http.StatusOK},
+		{"No params", url.Values{}, "", 7, http.StatusOK},
+		{"As an author", url.Values{}, s.Token, 10, http.StatusOK},
+		{"Drafts as an author", url.Values{"published": {"false"}}, s.Token, 3, http.StatusOK},
+		{"Drafts as anonymous", url.Values{"published": {"false"}}, "", 7, http.StatusOK},
+		{"Sorted by title", url.Values{"sort": {"title"}}, "", 7, http.StatusOK},
+		{"Sorted by publication date", url.Values{"sort": {"-published_at"}}, s.Token, 7, http.StatusOK},
+		{"Limit", url.Values{"limit": {"5"}}, "", 5, http.StatusOK},
+		{"Invalid limit", url.Values{"limit": {"0"}}, "", 0, http.StatusBadRequest},
+		{"Invalid sort", url.Values{"sort": {"content"}}, "", 0, http.StatusBadRequest},
+		{"Invalid cursor", url.Values{"cursor": {"nope"}}, "", 0, http.StatusBadRequest},
+		{"Invalid author", url.Values{"author": {"nope"}}, "", 0, http.StatusBadRequest},
+		{"Invalid date", url.Values{"created_after": {"yesterday"}}, "", 0, http.StatusBadRequest},
 	}
 
 	for _, tc := range tests {
 		t.Run(tc.description, func(t *testing.T) {
-			rec := callHandlerList(t)
+			rec := callHandlerList(t, store, tc.query, tc.token)
 			assert.Equal(t, tc.code, rec.Code)
 
 			if rec.Code == http.StatusOK {
-				var body []*articles.Article
+				var body articles.ListPayload
+				if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+					t.Fatal(err)
+				}
+
+				assert.Equal(t, tc.countWanted, len(body.Data))
+
+				if tc.query.Get("sort") == "title" {
+					titles := make([]string, len(body.Data))
+					for i, a := range body.Data {
+						titles[i] = a.Title
+					}
+					assert.True(t, sort.StringsAreSorted(titles))
+				}
+			}
+		})
+	}
+}
+
+func TestHandlerListPagination(t *testing.T) {
+	store := articles.NewMemoryStore()
+	for i := 0; i < 7; i++ {
+		newMemoryArticle(t, store, true)
+	}
+
+	for _, sorting := range []string{"-created_at", "title"} {
+		t.Run(sorting, func(t *testing.T) {
+			seen := map[string]bool{}
+			query := url.Values{"limit": {"3"}, "sort": {sorting}}
+
+			for page := 0; page < 3; page++ {
+				rec := callHandlerList(t, store, query, "")
+				if !assert.Equal(t, http.StatusOK, rec.Code) {
+					return
+				}
+
+				var body articles.ListPayload
 				if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
 					t.Fatal(err)
 				}
 
-				assert.Equal(t, tc.countWanted, len(body))
+				for _, a := range body.Data {
+					assert.False(t, seen[a.ID], "article returned twice")
+					seen[a.ID] = true
+				}
+
+				// The last page contains 1 article
+				assert.Equal(t, page < 2, body.HasMore)
+				if !body.HasMore {
+					assert.Empty(t, body.NextCursor)
+					break
+				}
+				query.Set("cursor", body.NextCursor)
 			}
+
+			assert.Equal(t, 7, len(seen))
 		})
 	}
 }
 
-func callHandlerList(t *testing.T) *httptest.ResponseRecorder {
+func TestHandlerListInvalidParams(t *testing.T) {
+	query := url.Values{"limit": {"twenty"}, "published": {"maybe"}, "author": {"nope"}}
+	rec := callHandlerList(t, articles.NewMemoryStore(), query, "")
+	if !assert.Equal(t, http.StatusBadRequest, rec.Code) {
+		return
+	}
+
+	var pld errorPayload
+	if err := json.NewDecoder(rec.Body).Decode(&pld); err != nil {
+		t.Fatal(err)
+	}
+
+	// All the invalid params are expected to be returned at once
+	assert.Equal(t, apierror.TypeValidation, pld.Error.Code)
+	if assert.Equal(t, 3, len(pld.Error.Details)) {
+		assert.Equal(t, "limit", pld.Error.Details[0].Field)
+		assert.Equal(t, "query", pld.Error.Details[0].Source)
+		assert.Equal(t, apierror.TypeInvalidParam, pld.Error.Details[0].Code)
+		assert.Equal(t, "published", pld.Error.Details[1].Field)
+		assert.Equal(t, "author", pld.Error.Details[2].Field)
+	}
+}
+
+func TestHandlerListInvalidParam(t *testing.T) {
+	rec := callHandlerList(t, articles.NewMemoryStore(), url.Values{"limit": {"twenty"}}, "")
+	if !assert.Equal(t, http.StatusBadRequest, rec.Code) {
+		return
+	}
+
+	var pld errorPayload
+	if err := json.NewDecoder(rec.Body).Decode(&pld); err != nil {
+		t.Fatal(err)
+	}
+
+	// A single invalid param is not wrapped in a validation error
+	assert.Equal(t, apierror.TypeInvalidParam, pld.Error.Code)
+	assert.Equal(t, "limit", pld.Error.Field)
+	assert.Empty(t, pld.Error.Details)
+}
+
+func callHandlerList(t *testing.T, store articles.ArticleStore, query url.Values, token string) *httptest.ResponseRecorder {
 	ri := &testhelpers.RequestInfo{
-		Test:     t,
-		Endpoint: articles.Endpoints[articles.EndpointList],
-		URI:      "/blog/articles/",
+		Test:        t,
+		App:         testApp,
+		Endpoint:    articles.Endpoints[articles.EndpointList],
+		URI:         "/blog/articles/?" + query.Encode(),
+		Token:       token,
+		Middlewares: []router.Middleware{articles.UseStore(store)},
 	}
 
 	return testhelpers.NewRequest(ri)
 }
+
+// newMemoryArticle adds an article with a random title to the given store
+func newMemoryArticle(t *testing.T, store articles.ArticleStore, published bool) *articles.Article {
+	a := &articles.Article{
+		Title:       uniuri.New(),
+		IsPublished: published,
+	}
+
+	if err := store.Create(a); err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
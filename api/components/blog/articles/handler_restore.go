@@ -0,0 +1,34 @@
+package articles
+
+import (
+	"github.com/Nivl/api.melvin.la/api/apierror"
+	"github.com/Nivl/api.melvin.la/api/router"
+)
+
+// HandlerRestoreParams represents the params accepted by HandlerRestore
+type HandlerRestoreParams struct {
+	ID string `from:"url" json:"id" params:"required,trim"`
+}
+
+// HandlerRestore represents a API handler to move an article out of the trash
+func HandlerRestore(req *router.Request) {
+	params, ok := req.Params.(*HandlerRestoreParams)
+	if !ok {
+		req.Error(apierror.NewServerError("Couldn't cast params"))
+		return
+	}
+
+	s := store(req)
+	a, err := s.Get(params.ID, trashSearch)
+	if err != nil {
+		req.Error(err)
+		return
+	}
+
+	if err := s.Restore(a); err != nil {
+		req.Error(err)
+		return
+	}
+
+	req.Ok(NewPayloadFromModel(a))
+}
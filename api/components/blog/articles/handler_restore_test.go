@@ -0,0 +1,68 @@
+package articles_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Nivl/api.melvin.la/api/app/testhelpers"
+	"github.com/Nivl/api.melvin.la/api/components/blog/articles"
+	"github.com/Nivl/api.melvin.la/api/components/users"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/mgo.v2/bson"
+)
+
+func TestHandlerRestore(t *testing.T) {
+	u, s := users.NewTestAuth(t, testApp.DB, users.RoleAuthor)
+	testhelpers.SaveModel(t, testApp.DB, u)
+	testhelpers.SaveModel(t, testApp.DB, s)
+
+	a := articles.NewTestArticle(t, testApp.DB, nil)
+	testhelpers.SaveModel(t, testApp.DB, a)
+
+	trashed := articles.NewTestArticle(t, testApp.DB, &articles.Article{IsDeleted: true, DeletedAt: time.Now()})
+	testhelpers.SaveModel(t, testApp.DB, trashed)
+
+	defer testhelpers.PurgeModels(t)
+
+	tests := []struct {
+		description string
+		id          string
+		code        int
+	}{
+		{"Unknown article", bson.NewObjectId().Hex(), http.StatusNotFound},
+		{"Not in the trash", a.ID.Hex(), http.StatusNotFound},
+		{"Article in the trash", trashed.ID.Hex(), http.StatusOK},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			rec := callHandlerRestore(t, tc.id, s.Token)
+			assert.Equal(t, tc.code, rec.Code)
+
+			if rec.Code == http.StatusOK {
+				var pld articles.Exportable
+				if err := json.NewDecoder(rec.Body).Decode(&pld); err != nil {
+					t.Fatal(err)
+				}
+
+				assert.Equal(t, tc.id, pld.ID)
+				assert.Empty(t, pld.DeletedAt)
+			}
+		})
+	}
+}
+
+func callHandlerRestore(t *testing.T, id string, token string) *httptest.ResponseRecorder {
+	ri := &testhelpers.RequestInfo{
+		Test:     t,
+		App:      testApp,
+		Endpoint: articles.Endpoints[articles.EndpointRestore],
+		URI:      "/blog/articles/" + id + "/restore",
+		Token:    token,
+	}
+
+	return testhelpers.NewRequest(ri)
+}
@@ -0,0 +1,28 @@
+package articles
+
+import (
+	"github.com/Nivl/api.melvin.la/api/router"
+)
+
+// HandlerListTrash represents a API handler to get the list of articles
+// in the trash
+func HandlerListTrash(req *router.Request) {
+	arts, err := store(req).ListTrash()
+	if err != nil {
+		req.Error(err)
+		return
+	}
+
+	req.Ok(NewPayloadFromModels(arts))
+}
+
+// HandlerPurgeTrash represents a API handler to fully delete the articles
+// that have been in the trash for longer than the retention period
+func HandlerPurgeTrash(req *router.Request) {
+	if _, err := store(req).PurgeTrash(req.App.Params.TrashRetention); err != nil {
+		req.Error(err)
+		return
+	}
+
+	req.NoContent()
+}
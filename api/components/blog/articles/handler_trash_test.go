@@ -0,0 +1,87 @@
+package articles_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Nivl/api.melvin.la/api/app/testhelpers"
+	"github.com/Nivl/api.melvin.la/api/components/blog/articles"
+	"github.com/Nivl/api.melvin.la/api/components/users"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandlerListTrash(t *testing.T) {
+	u, s := users.NewTestAuth(t, testApp.DB, users.RoleAdmin)
+	testhelpers.SaveModel(t, testApp.DB, u)
+	testhelpers.SaveModel(t, testApp.DB, s)
+
+	for i := 0; i < 3; i++ {
+		a := articles.NewTestArticle(t, testApp.DB, &articles.Article{IsDeleted: true, DeletedAt: time.Now()})
+		testhelpers.SaveModel(t, testApp.DB, a)
+	}
+
+	a := articles.NewTestArticle(t, testApp.DB, nil)
+	testhelpers.SaveModel(t, testApp.DB, a)
+
+	defer testhelpers.PurgeModels(t)
+
+	editorUser, editor := users.NewTestAuth(t, testApp.DB, users.RoleEditor)
+	testhelpers.SaveModel(t, testApp.DB, editorUser)
+	testhelpers.SaveModel(t, testApp.DB, editor)
+
+	rec := callHandler(t, articles.EndpointListTrash, "/blog/articles/trash", editor.Token)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	rec = callHandler(t, articles.EndpointListTrash, "/blog/articles/trash", s.Token)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var body []*articles.Exportable
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, pld := range body {
+		assert.NotEqual(t, a.ID.Hex(), pld.ID)
+		assert.NotEmpty(t, pld.DeletedAt)
+	}
+}
+
+func TestHandlerPurgeTrash(t *testing.T) {
+	u, s := users.NewTestAuth(t, testApp.DB, users.RoleAdmin)
+	testhelpers.SaveModel(t, testApp.DB, u)
+	testhelpers.SaveModel(t, testApp.DB, s)
+
+	expired := articles.NewTestArticle(t, testApp.DB, &articles.Article{
+		IsDeleted: true,
+		DeletedAt: time.Now().Add(-24 * 365 * time.Hour),
+	})
+
+	recent := articles.NewTestArticle(t, testApp.DB, &articles.Article{IsDeleted: true, DeletedAt: time.Now()})
+	testhelpers.SaveModel(t, testApp.DB, recent)
+
+	defer testhelpers.PurgeModels(t)
+
+	rec := callHandler(t, articles.EndpointPurgeTrash, "/blog/articles/trash", s.Token)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+
+	_, err := articles.NewMongoStore(testApp.DB).Get(expired.ID.Hex(), nil)
+	assert.Error(t, err)
+
+	_, err = articles.NewMongoStore(testApp.DB).Get(recent.ID.Hex(), nil)
+	assert.NoError(t, err)
+}
+
+func callHandler(t *testing.T, endpoint int, uri string, token string) *httptest.ResponseRecorder {
+	ri := &testhelpers.RequestInfo{
+		Test:     t,
+		App:      testApp,
+		Endpoint: articles.Endpoints[endpoint],
+		URI:      uri,
+		Token:    token,
+	}
+
+	return testhelpers.NewRequest(ri)
+}
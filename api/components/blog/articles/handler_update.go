@@ -1,31 +1,74 @@
 package articles
 
-import "github.com/Nivl/api.melvin.la/api/router"
+import (
+	"github.com/Nivl/api.melvin.la/api/apierror"
+	"github.com/Nivl/api.melvin.la/api/components/users"
+	"github.com/Nivl/api.melvin.la/api/router"
+)
+
+// HandlerUpdateParams represents the params accepted by HandlerUpdate.
+// A nil field means the field has not been provided and will not be updated
+type HandlerUpdateParams struct {
+	ID          string   `from:"url" json:"id" params:"required,trim"`
+	Title       *string  `from:"form" json:"title,omitempty" params:"trim,min_len=1,max_len=255"`
+	Subtitle    *string  `from:"form" json:"subtitle,omitempty" params:"max_len=255"`
+	Description *string  `from:"form" json:"description,omitempty"`
+	Content     *string  `from:"form" json:"content,omitempty"`
+	Slug        *string  `from:"form" json:"slug,omitempty" params:"trim,slug,max_len=255"`
+	IsPublished *bool    `from:"form" json:"is_published,omitempty"`
+	Tags        []string `from:"form" json:"tags,omitempty"`
+}
 
 // HandlerUpdate represents a API handler to update an article
 func HandlerUpdate(req *router.Request) {
-	//appCtx := app.GetContext()
-	//doc := appCtx.DB.C("article")
-	//article := Article{
-	//	ID:        bson.NewObjectId(),
-	//	CreatedAt: time.Now(),
-	//}
-	//
-	//if err := gin.Bind(&article); err != nil {
-	//	req.BadRequest(err)
-	//	return
-	//}
-	//
-	//if err := doc.Insert(article); err != nil {
-	//	if mgo.IsDup(err) {
-	//		req.Conflict("The slug %s already exists in the database", article.Slug)
-	//		return
-	//	}
-	//
-	//	req.ServerError(err)
-	//	return
-	//}
-
-	//httpResponse.Ok(gin, httpResponse.Resource{article})
-	req.NoContent()
+	params, ok := req.Params.(*HandlerUpdateParams)
+	if !ok {
+		req.Error(apierror.NewServerError("Couldn't cast params"))
+		return
+	}
+
+	a, err := store(req).Get(params.ID, defaultSearch)
+	if err != nil {
+		req.Error(err)
+		return
+	}
+
+	if params.Title != nil {
+		a.Title = *params.Title
+	}
+
+	if params.Subtitle != nil {
+		a.Subtitle = *params.Subtitle
+	}
+
+	if params.Description != nil {
+		a.Description = *params.Description
+	}
+
+	if params.Content != nil {
+		a.Content = *params.Content
+	}
+
+	if params.Tags != nil {
+		a.SetTags(params.Tags)
+	}
+
+	if params.IsPublished != nil && *params.IsPublished != a.IsPublished {
+		if !req.User.HasPermission(users.PermissionArticlesPublish) {
+			req.Error(apierror.NewForbidden("permission [%s] required", users.PermissionArticlesPublish))
+			return
+		}
+		a.IsPublished = *params.IsPublished
+	}
+
+	if params.Slug != nil {
+		a.Slug = *params.Slug
+	}
+
+	if err := store(req).Update(a); err != nil {
+		req.Error(err)
+		return
+	}
+
+	req.Ok(NewPayloadFromModel(a))
 }
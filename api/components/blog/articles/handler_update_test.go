@@ -0,0 +1,145 @@
+package articles_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Nivl/api.melvin.la/api/apierror"
+	"github.com/Nivl/api.melvin.la/api/app/testhelpers"
+	"github.com/Nivl/api.melvin.la/api/components/blog/articles"
+	"github.com/Nivl/api.melvin.la/api/components/users"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/mgo.v2/bson"
+)
+
+func TestHandlerUpdate(t *testing.T) {
+	u, s := users.NewTestAuth(t, testApp.DB, users.RoleEditor)
+	testhelpers.SaveModel(t, testApp.DB, u)
+	testhelpers.SaveModel(t, testApp.DB, s)
+
+	noPermsUser, noPerms := users.NewTestAuth(t, testApp.DB)
+	testhelpers.SaveModel(t, testApp.DB, noPermsUser)
+	testhelpers.SaveModel(t, testApp.DB, noPerms)
+
+	authorUser, author := users.NewTestAuth(t, testApp.DB, users.RoleAuthor)
+	testhelpers.SaveModel(t, testApp.DB, authorUser)
+	testhelpers.SaveModel(t, testApp.DB, author)
+
+	a := articles.NewTestArticle(t, testApp.DB, &articles.Article{Subtitle: "subtitle", IsPublished: false})
+	testhelpers.SaveModel(t, testApp.DB, a)
+
+	other := articles.NewTestArticle(t, testApp.DB, nil)
+	testhelpers.SaveModel(t, testApp.DB, other)
+
+	defer testhelpers.PurgeModels(t)
+
+	// The subtitle is only changed by the cases providing one
+	subtitle := a.Subtitle
+
+	tests := []struct {
+		description string
+		id          string
+		params      *articles.HandlerUpdateParams
+		token       string
+		code        int
+		field       string
+	}{
+		{"No auth", a.ID.Hex(), &articles.HandlerUpdateParams{}, "", http.StatusUnauthorized, ""},
+		{"No permission", a.ID.Hex(), &articles.HandlerUpdateParams{}, noPerms.Token, http.StatusForbidden, ""},
+		{"Author cannot publish", a.ID.Hex(), &articles.HandlerUpdateParams{IsPublished: boolPtr(true)}, author.Token, http.StatusForbidden, ""},
+		{"Author updating content", a.ID.Hex(), &articles.HandlerUpdateParams{Content: strPtr("content")}, author.Token, http.StatusOK, ""},
+		{"Unknown article", bson.NewObjectId().Hex(), &articles.HandlerUpdateParams{}, s.Token, http.StatusNotFound, ""},
+		{"Empty title", a.ID.Hex(), &articles.HandlerUpdateParams{Title: strPtr("  ")}, s.Token, http.StatusBadRequest, "title"},
+		{"ObjectId as slug", a.ID.Hex(), &articles.HandlerUpdateParams{Slug: strPtr(bson.NewObjectId().Hex())}, s.Token, http.StatusBadRequest, "slug"},
+		{"Invalid slug", a.ID.Hex(), &articles.HandlerUpdateParams{Slug: strPtr("Not a slug")}, s.Token, http.StatusBadRequest, "slug"},
+		{"Empty slug", a.ID.Hex(), &articles.HandlerUpdateParams{Slug: strPtr("")}, s.Token, http.StatusBadRequest, "slug"},
+		{"Title too long", a.ID.Hex(), &articles.HandlerUpdateParams{Title: strPtr(strings.Repeat("a", 256))}, s.Token, http.StatusBadRequest, "title"},
+		{"Duplicate slug", a.ID.Hex(), &articles.HandlerUpdateParams{Slug: strPtr(other.Slug)}, s.Token, http.StatusConflict, ""},
+		{"No params", a.ID.Hex(), &articles.HandlerUpdateParams{}, s.Token, http.StatusOK, ""},
+		{"New title", a.ID.Hex(), &articles.HandlerUpdateParams{Title: strPtr("New title")}, s.Token, http.StatusOK, ""},
+		{"Empty subtitle", a.ID.Hex(), &articles.HandlerUpdateParams{Subtitle: strPtr("")}, s.Token, http.StatusOK, ""},
+		{"Publish", a.Slug, &articles.HandlerUpdateParams{IsPublished: boolPtr(true)}, s.Token, http.StatusOK, ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			rec := callHandlerUpdate(t, tc.id, tc.params, tc.token)
+			assert.Equal(t, tc.code, rec.Code)
+
+			if rec.Code == http.StatusOK {
+				var pld articles.Exportable
+				if err := json.NewDecoder(rec.Body).Decode(&pld); err != nil {
+					t.Fatal(err)
+				}
+
+				assert.Equal(t, a.ID.Hex(), pld.ID)
+				assert.NotEmpty(t, pld.UpdatedAt)
+
+				if tc.params.Title != nil {
+					assert.Equal(t, *tc.params.Title, pld.Title)
+				}
+
+				if tc.params.Subtitle != nil {
+					subtitle = *tc.params.Subtitle
+				}
+				assert.Equal(t, subtitle, pld.Subtitle)
+
+				if tc.params.IsPublished != nil {
+					assert.Equal(t, *tc.params.IsPublished, pld.IsPublished)
+				}
+			}
+
+			if rec.Code == http.StatusBadRequest {
+				var pld errorPayload
+				if err := json.NewDecoder(rec.Body).Decode(&pld); err != nil {
+					t.Fatal(err)
+				}
+
+				assert.Equal(t, tc.field, pld.invalidField())
+				assert.Equal(t, rec.Header().Get("X-Request-Id"), pld.RequestID)
+			}
+		})
+	}
+}
+
+func callHandlerUpdate(t *testing.T, id string, params *articles.HandlerUpdateParams, token string) *httptest.ResponseRecorder {
+	ri := &testhelpers.RequestInfo{
+		Test:     t,
+		App:      testApp,
+		Endpoint: articles.Endpoints[articles.EndpointUpdate],
+		URI:      "/blog/articles/" + id,
+		Params:   params,
+		Token:    token,
+	}
+
+	return testhelpers.NewRequest(ri)
+}
+
+// errorPayload represents the body of an error returned by the API
+type errorPayload struct {
+	Error struct {
+		Code    string                 `json:"code"`
+		Field   string                 `json:"field"`
+		Details []*apierror.FieldError `json:"details"`
+	} `json:"error"`
+	RequestID string `json:"request_id"`
+}
+
+// invalidField returns the name of the first invalid param
+func (pld *errorPayload) invalidField() string {
+	if pld.Error.Code == apierror.TypeValidation && len(pld.Error.Details) > 0 {
+		return pld.Error.Details[0].Field
+	}
+	return pld.Error.Field
+}
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
@@ -0,0 +1,177 @@
+package articles
+
+import (
+	"strings"
+	"time"
+
+	"github.com/Nivl/api.melvin.la/api/apierror"
+	"gopkg.in/mgo.v2/bson"
+)
+
+const (
+	// DefaultSort is the sort used when none is provided
+	DefaultSort = "-created_at"
+
+	// MaxListLimit is the maximum number of articles that can be retrieved at once
+	MaxListLimit = 100
+)
+
+// sortableFields contains the fields the articles can be sorted on
+var sortableFields = map[string]bool{
+	"created_at":   true,
+	"published_at": true,
+	"title":        true,
+}
+
+// ListFilters contains the filters that can be applied when listing articles
+type ListFilters struct {
+	// Published filters on the publication state. nil means all the articles
+	Published *bool
+
+	Tag           string
+	AuthorID      bson.ObjectId
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+}
+
+// ListOptions contains the options used to list articles
+type ListOptions struct {
+	Filters ListFilters
+
+	// Sort contains the field to sort on, prefixed by "-" for a
+	// descending order
+	Sort string
+
+	// Limit contains the maximum number of articles to return
+	Limit int
+
+	// Cursor contains the position of the last article of the previous page
+	Cursor *Cursor
+}
+
+// ListResult contains a page of articles
+type ListResult struct {
+	Articles []*Article
+
+	// Next contains the cursor to use to get the next page. nil if there is
+	// no more pages
+	Next *Cursor
+}
+
+// parseSort returns the field and the order of a sort
+func parseSort(sort string) (field string, desc bool, err error) {
+	if sort == "" {
+		sort = DefaultSort
+	}
+
+	desc = strings.HasPrefix(sort, "-")
+	field = strings.TrimPrefix(sort, "-")
+
+	if !sortableFields[field] {
+		return "", false, apierror.NewInvalidParam("sort", "value [%s] for parameter [sort] is invalid", sort)
+	}
+
+	return field, desc, nil
+}
+
+// normalize checks the options and sets their default values. The field
+// to sort on and the order are returned
+func (opts *ListOptions) normalize() (field string, desc bool, err error) {
+	if opts.Sort == "" {
+		opts.Sort = DefaultSort
+	}
+
+	field, desc, err = parseSort(opts.Sort)
+	if err != nil {
+		return "", false, err
+	}
+
+	if opts.Limit < 1 {
+		return "", false, apierror.NewInvalidParam("limit", "parameter [limit] must be greater than 0")
+	}
+
+	if opts.Limit > MaxListLimit {
+		opts.Limit = MaxListLimit
+	}
+
+	if opts.Cursor != nil && opts.Cursor.Sort != opts.Sort {
+		return "", false, apierror.NewInvalidParam("cursor", "the cursor cannot be used with the sort [%s]", opts.Sort)
+	}
+
+	return field, desc, nil
+}
+
+// newListResult returns a page of articles from the given list. The list is
+// expected to contain one more article than needed when there's a next page
+func newListResult(arts []*Article, opts *ListOptions, field string) *ListResult {
+	res := &ListResult{Articles: arts}
+	if len(arts) > opts.Limit {
+		res.Articles = arts[:opts.Limit]
+		res.Next = newCursor(opts.Sort, field, res.Articles[opts.Limit-1])
+	}
+	return res
+}
+
+// query returns the Mongo query matching the filters
+func (f *ListFilters) query() bson.M {
+	query := bson.M{"is_deleted": false}
+
+	if f.Published != nil {
+		query["is_published"] = *f.Published
+	}
+
+	if f.Tag != "" {
+		query["tags"] = strings.ToLower(f.Tag)
+	}
+
+	if f.AuthorID != "" {
+		query["author_id"] = f.AuthorID
+	}
+
+	createdAt := bson.M{}
+	if !f.CreatedAfter.IsZero() {
+		createdAt["$gte"] = f.CreatedAfter
+	}
+	if !f.CreatedBefore.IsZero() {
+		createdAt["$lt"] = f.CreatedBefore
+	}
+	if len(createdAt) > 0 {
+		query["created_at"] = createdAt
+	}
+
+	return query
+}
+
+// newCursor returns a cursor pointing to the given article
+func newCursor(sort string, field string, a *Article) *Cursor {
+	c := &Cursor{Sort: sort, ID: a.ID}
+
+	switch field {
+	case "created_at":
+		c.Time = a.CreatedAt
+	case "published_at":
+		c.Time = a.PublishedAt
+	case "title":
+		c.Text = a.Title
+	}
+
+	return c
+}
+
+// cursorQuery returns the query matching all the articles after the cursor
+func cursorQuery(field string, desc bool, c *Cursor) bson.M {
+	var value interface{} = c.Time
+	if field == "title" {
+		value = c.Text
+	}
+
+	operator := "$gt"
+	if desc {
+		operator = "$lt"
+	}
+
+	return bson.M{"$or": []bson.M{
+		{field: bson.M{operator: value}},
+		{field: value, "_id": bson.M{operator: c.ID}},
+	}}
+}
@@ -0,0 +1,157 @@
+package articles
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/Nivl/api.melvin.la/api/migration"
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// Migrations contains the changes made to the Articles documents
+var Migrations = []*migration.Migration{
+	{
+		Version:     20261016120000,
+		Description: "move the articles and their indexes to the article collection",
+		Up:          moveToArticleUp,
+		Down:        moveToArticleDown,
+	},
+}
+
+// legacyCollection is the collection the indexes used to be created on
+const legacyCollection = "articles"
+
+// moveToArticleCollection and moveToArticleIndexes contain the collection
+// and the indexes of the articles as they were when the migration has been
+// written. The migration needs to keep doing the same thing when the model
+// changes, so it doesn't use Query() nor the current indexes
+const moveToArticleCollection = "article"
+
+var moveToArticleIndexes = []mgo.Index{
+	{Key: []string{"slug"}, Unique: true, Background: true},
+	{Key: []string{"-created_at"}, Background: true},
+	{Key: []string{"-published_at"}, Background: true},
+	{Key: []string{"title"}, Background: true},
+	{Key: []string{"tags"}, Background: true},
+	{Key: []string{"author_id"}, Background: true},
+}
+
+// moveToArticleUp moves the documents of the legacy collection to the one
+// used by the model, renames the duplicated slugs so the unique index can
+// be created, then creates the indexes and drops the legacy collection
+func moveToArticleUp(db *mgo.Database) error {
+	names, err := db.CollectionNames()
+	if err != nil {
+		return err
+	}
+
+	hasLegacy := false
+	for _, name := range names {
+		hasLegacy = hasLegacy || name == legacyCollection
+	}
+
+	if hasLegacy {
+		if err := moveLegacyArticles(db); err != nil {
+			return err
+		}
+	}
+
+	if err := renameDuplicatedSlugs(db); err != nil {
+		return err
+	}
+
+	for _, index := range moveToArticleIndexes {
+		if err := db.C(moveToArticleCollection).EnsureIndex(index); err != nil {
+			return err
+		}
+	}
+
+	if hasLegacy {
+		return db.C(legacyCollection).DropCollection()
+	}
+	return nil
+}
+
+// moveToArticleDown removes the indexes of the article collection. The
+// moved documents and the renamed slugs are not reverted
+func moveToArticleDown(db *mgo.Database) error {
+	for _, index := range moveToArticleIndexes {
+		if err := db.C(moveToArticleCollection).DropIndex(index.Key...); err != nil {
+			return fmt.Errorf("could not drop the index %v: %s", index.Key, err.Error())
+		}
+	}
+	return nil
+}
+
+// moveLegacyArticles copies the documents of the legacy collection to the
+// article collection. The documents already in the collection are skipped
+func moveLegacyArticles(db *mgo.Database) error {
+	iter := db.C(legacyCollection).Find(nil).Iter()
+
+	doc := bson.M{}
+	for iter.Next(&doc) {
+		if err := db.C(moveToArticleCollection).Insert(doc); err != nil && !mgo.IsDup(err) {
+			iter.Close()
+			return err
+		}
+		doc = bson.M{}
+	}
+
+	return iter.Close()
+}
+
+// renameDuplicatedSlugs appends "-X" to the slugs used by more than one
+// article, like Create() does. The oldest article keeps the original slug
+func renameDuplicatedSlugs(db *mgo.Database) error {
+	pipeline := []bson.M{
+		{"$group": bson.M{"_id": "$slug", "ids": bson.M{"$push": "$_id"}, "count": bson.M{"$sum": 1}}},
+		{"$match": bson.M{"count": bson.M{"$gt": 1}}},
+	}
+
+	duplicates := []struct {
+		Slug string          `bson:"_id"`
+		IDs  []bson.ObjectId `bson:"ids"`
+	}{}
+	if err := db.C(moveToArticleCollection).Pipe(pipeline).All(&duplicates); err != nil {
+		return err
+	}
+
+	for _, dup := range duplicates {
+		sort.Slice(dup.IDs, func(i, j int) bool {
+			return dup.IDs[i] < dup.IDs[j]
+		})
+
+		suffix := 0
+		for _, id := range dup.IDs[1:] {
+			slug, err := unusedSlug(db, dup.Slug, &suffix)
+			if err != nil {
+				return err
+			}
+
+			if err := db.C(moveToArticleCollection).UpdateId(id, bson.M{"$set": bson.M{"slug": slug}}); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// unusedSlug returns the first slug made of the given slug and a suffix
+// that is not used yet. suffix is updated to be reused on the next call
+func unusedSlug(db *mgo.Database, slug string, suffix *int) (string, error) {
+	for ; ; *suffix++ {
+		candidate := fmt.Sprintf("%s-%d", slug, *suffix)
+
+		count, err := db.C(moveToArticleCollection).Find(bson.M{"slug": candidate}).Count()
+		if err != nil {
+			return "", err
+		}
+
+		if count == 0 {
+			*suffix++
+			return candidate, nil
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package articles_test
+
+import (
+	"testing"
+
+	"github.com/Nivl/api.melvin.la/api/components/blog/articles"
+	"github.com/stretchr/testify/assert"
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+func TestMigrationMoveToArticle(t *testing.T) {
+	// The migration works on the whole collection, so we use a database that
+	// is not shared with the other tests
+	db := testApp.Session.DB(testApp.DB.Name + "_article_migration")
+	defer db.DropDatabase()
+
+	// The IDs are created in order, the oldest article keeps its slug
+	ids := make([]bson.ObjectId, 5)
+	for i := range ids {
+		ids[i] = bson.NewObjectId()
+	}
+
+	docs := []struct {
+		collection string
+		doc        bson.M
+	}{
+		{"article", bson.M{"_id": ids[0], "slug": "hello"}},
+		{"article", bson.M{"_id": ids[1], "slug": "hello"}},
+		{"article", bson.M{"_id": ids[2], "slug": "hello-0"}},
+		{"articles", bson.M{"_id": ids[0], "slug": "hello"}},
+		{"articles", bson.M{"_id": ids[3], "slug": "hello"}},
+		{"articles", bson.M{"_id": ids[4], "slug": "world"}},
+	}
+	for _, d := range docs {
+		if err := db.C(d.collection).Insert(d.doc); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	m := articles.Migrations[0]
+	if err := m.Up(db); err != nil {
+		t.Fatal(err)
+	}
+
+	// The legacy documents have been moved, and the duplicated slugs renamed
+	expected := map[bson.ObjectId]string{
+		ids[0]: "hello",
+		ids[1]: "hello-1",
+		ids[2]: "hello-0",
+		ids[3]: "hello-2",
+		ids[4]: "world",
+	}
+	moved := []bson.M{}
+	if err := db.C("article").Find(nil).All(&moved); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, len(expected), len(moved))
+	for _, doc := range moved {
+		id := doc["_id"].(bson.ObjectId)
+		assert.Equal(t, expected[id], doc["slug"], "slug of %s", id.Hex())
+	}
+
+	names, err := db.CollectionNames()
+	assert.NoError(t, err)
+	assert.NotContains(t, names, "articles")
+
+	// The slugs are now unique
+	err = db.C("article").Insert(bson.M{"slug": "world"})
+	assert.True(t, mgo.IsDup(err), "expected a duplicate error, got %v", err)
+
+	if err := m.Down(db); err != nil {
+		t.Fatal(err)
+	}
+	indexes, err := db.C("article").Indexes()
+	assert.NoError(t, err)
+	if assert.Equal(t, 1, len(indexes), "only the _id index should be left") {
+		assert.Equal(t, []string{"_id"}, indexes[0].Key)
+	}
+
+	// Up can be applied again on a database without legacy collection
+	assert.NoError(t, m.Up(db))
+}
@@ -3,107 +3,145 @@ package articles
 import (
 	"errors"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/Nivl/api.melvin.la/api/apierror"
-	"github.com/Nivl/api.melvin.la/api/app"
 	"github.com/dchest/uniuri"
 	"github.com/gosimple/slug"
 	mgo "gopkg.in/mgo.v2"
 	"gopkg.in/mgo.v2/bson"
 )
 
-func Query() *mgo.Collection {
-	return app.GetContext().DB.C("article")
+// Query returns the collection of the articles in the given database
+func Query(db *mgo.Database) *mgo.Collection {
+	return db.C("article")
 }
 
-var defaultSearch = bson.M{
-	"is_deleted": false,
+var (
+	yes = true
+	no  = false
+)
+
+// defaultSearch contains the filters used to get the articles that are not
+// in the trash
+var defaultSearch = &Filters{
+	Deleted: &no,
 }
 
-// Article is a structure representing an article that can be saved in the database
-type Article struct {
-	ID          bson.ObjectId `bson:"_id"`
-	Title       string        `bson:"title"`
-	Content     string        `bson:"content"`
-	Slug        string        `bson:"slug"`
-	Subtitle    string        `bson:"subtitle"`
-	Description string        `bson:"description"`
-	CreatedAt   time.Time     `bson:"created_at"`
-	IsDeleted   bool          `bson:"is_deleted"`
-	IsPublished bool          `bson:"is_published"`
+// trashSearch contains the filters used to get the articles in the trash
+var trashSearch = &Filters{
+	Deleted: &yes,
 }
 
-func (a *Article) FullyDelete() error {
-	if a == nil {
-		return errors.New("article not instanced")
-	}
+// reservedSlugs contains the slugs that would collide with a route
+var reservedSlugs = map[string]bool{
+	"trash": true,
+}
 
-	if a.ID == "" {
-		return errors.New("article has not been saved")
-	}
+// publicSearch contains the filters used to only get the articles that can be
+// seen by anyone
+var publicSearch = &Filters{
+	Deleted:   &no,
+	Published: &yes,
+}
 
-	return Query().RemoveId(a.ID)
+// Article is a structure representing an article that can be saved in the database.
+// The json tags are used by the backups, the API uses Exportable
+type Article struct {
+	ID          bson.ObjectId `bson:"_id" json:"id,omitempty"`
+	Title       string        `bson:"title" json:"title"`
+	Content     string        `bson:"content" json:"content"`
+	Slug        string        `bson:"slug" json:"slug"`
+	Subtitle    string        `bson:"subtitle" json:"subtitle"`
+	Description string        `bson:"description" json:"description"`
+	Tags        []string      `bson:"tags" json:"tags"`
+	AuthorID    bson.ObjectId `bson:"author_id,omitempty" json:"author_id,omitempty"`
+	CreatedAt   time.Time     `bson:"created_at" json:"created_at"`
+	UpdatedAt   time.Time     `bson:"updated_at,omitempty" json:"updated_at"`
+	PublishedAt time.Time     `bson:"published_at,omitempty" json:"published_at"`
+	DeletedAt   time.Time     `bson:"deleted_at,omitempty" json:"deleted_at"`
+	IsDeleted   bool          `bson:"is_deleted" json:"is_deleted"`
+	IsPublished bool          `bson:"is_published" json:"is_published"`
 }
 
-func (a *Article) Save() error {
-	if a == nil {
-		return errors.New("article not instanced")
-	}
+// SetTags normalizes and sets the tags of the article. Empty and duplicate
+// tags are removed
+func (a *Article) SetTags(tags []string) {
+	a.Tags = make([]string, 0, len(tags))
+	found := map[string]bool{}
 
-	if a.ID == "" {
-		return a.Create()
-	}
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || found[tag] {
+			continue
+		}
 
-	return a.Update()
+		found[tag] = true
+		a.Tags = append(a.Tags, tag)
+	}
 }
 
-func (a *Article) Create() error {
-	if a == nil {
-		return apierror.NewServerError("article not instanced")
+// setPublicationDate sets the date of the first publication of the article
+func (a *Article) setPublicationDate() {
+	if a.IsPublished && a.PublishedAt.IsZero() {
+		a.PublishedAt = time.Now()
 	}
+}
 
+// setSlug generates the slug of the article if needed, and makes sure it
+// can be used
+func (a *Article) setSlug() error {
 	if a.Slug == "" {
 		a.Slug = slug.Make(a.Title)
+
+		// The user did not choose this slug, so we make it usable the same way
+		// Create() does with the duplicates, instead of rejecting it
+		if bson.IsObjectIdHex(a.Slug) || reservedSlugs[a.Slug] {
+			a.Slug = fmt.Sprintf("%s-%d", a.Slug, 0)
+		}
+		return nil
 	}
 
 	if bson.IsObjectIdHex(a.Slug) {
-		return apierror.NewBadRequest("slug cannot be a ObjectId")
+		return apierror.NewInvalidParam("slug", "slug cannot be a ObjectId")
 	}
 
-	a.CreatedAt = time.Now()
-
-	// To prevent duplicates on the slug, we'll retry the insert() up to 10 times
-	originalSlug := a.Slug
-	var err error
-	for i := 0; i < 10; i++ {
-		a.ID = bson.NewObjectId()
-		err = Query().Insert(a)
-
-		if err != nil {
-			// In case of duplicate we'll add "-X" at the end of the slug, where X is
-			// a number
-			a.Slug = fmt.Sprintf("%s-%d", originalSlug, i)
-
-			if mgo.IsDup(err) == false {
-				return apierror.NewServerError(err.Error())
-			}
-		} else {
-			// everything went well
-			return nil
-		}
+	if reservedSlugs[a.Slug] {
+		return apierror.NewInvalidParam("slug", "slug [%s] is reserved", a.Slug)
 	}
 
-	// after 10 try we just return an error
-	return apierror.NewConflict(err.Error())
+	return nil
 }
 
-func (a *Article) Update() error {
-	return nil
+// FullyDelete removes the article from the given database
+func (a *Article) FullyDelete(db *mgo.Database) error {
+	if a == nil {
+		return errors.New("article not instanced")
+	}
+
+	if a.ID == "" {
+		return errors.New("article has not been saved")
+	}
+
+	return Query(db).RemoveId(a.ID)
+}
+
+// Save creates or updates the article in the given database
+func (a *Article) Save(db *mgo.Database) error {
+	if a == nil {
+		return errors.New("article not instanced")
+	}
+
+	if a.ID == "" {
+		return NewMongoStore(db).Create(a)
+	}
+
+	return NewMongoStore(db).Update(a)
 }
 
-func NewTestArticle(t *testing.T, a *Article) *Article {
+func NewTestArticle(t *testing.T, db *mgo.Database, a *Article) *Article {
 	if a == nil {
 		a = &Article{
 			IsDeleted:   false,
@@ -115,7 +153,7 @@ func NewTestArticle(t *testing.T, a *Article) *Article {
 		a.Title = uniuri.New()
 	}
 
-	if err := a.Save(); err != nil {
+	if err := a.Save(db); err != nil {
 		t.Fatalf("failed to save article: %s", err)
 	}
 	return a
@@ -4,25 +4,57 @@ import "github.com/Nivl/api.melvin.la/api/app/helpers"
 
 // Exportable represents an Article that can be safely returned by the API
 type Exportable struct {
-	Title       string `json:"title"`
-	Content     string `json:"content"`
-	Slug        string `json:"slug"`
-	Subtitle    string `json:"subtitle"`
-	Description string `json:"description"`
-	CreatedAt   string `json:"created_at"`
+	ID          string   `json:"id"`
+	Title       string   `json:"title"`
+	Content     string   `json:"content"`
+	Slug        string   `json:"slug"`
+	Subtitle    string   `json:"subtitle"`
+	Description string   `json:"description"`
+	Tags        []string `json:"tags"`
+	AuthorID    string   `json:"author_id,omitempty"`
+	CreatedAt   string   `json:"created_at"`
+	UpdatedAt   string   `json:"updated_at,omitempty"`
+	PublishedAt string   `json:"published_at,omitempty"`
+	DeletedAt   string   `json:"deleted_at,omitempty"`
+	IsPublished bool     `json:"is_published"`
 }
 
 // NewPayloadFromModel turns an Article into an object that is safe to be
 // returned by the API
 func NewPayloadFromModel(a *Article) *Exportable {
-	return &Exportable{
+	pld := &Exportable{
+		ID:          a.ID.Hex(),
 		Title:       a.Title,
 		Content:     a.Content,
 		Slug:        a.Slug,
 		Subtitle:    a.Subtitle,
 		Description: a.Description,
+		Tags:        a.Tags,
 		CreatedAt:   helpers.GetDateForJSON(a.CreatedAt),
+		IsPublished: a.IsPublished,
 	}
+
+	if pld.Tags == nil {
+		pld.Tags = []string{}
+	}
+
+	if a.AuthorID != "" {
+		pld.AuthorID = a.AuthorID.Hex()
+	}
+
+	if !a.PublishedAt.IsZero() {
+		pld.PublishedAt = helpers.GetDateForJSON(a.PublishedAt)
+	}
+
+	if !a.UpdatedAt.IsZero() {
+		pld.UpdatedAt = helpers.GetDateForJSON(a.UpdatedAt)
+	}
+
+	if a.IsDeleted {
+		pld.DeletedAt = helpers.GetDateForJSON(a.DeletedAt)
+	}
+
+	return pld
 }
 
 // NewPayloadFromModels turns a []*Article into a list object that is safe to be
@@ -1,15 +1,21 @@
 package articles
 
 import (
+	"github.com/Nivl/api.melvin.la/api/components/users"
 	"github.com/Nivl/api.melvin.la/api/router"
-	"github.com/gorilla/mux"
 )
 
+// The routes are matched in the order of this list, so the trash routes need
+// to be declared before the routes having an {id}
 const (
 	EndpointList = iota
+	EndpointListTrash
+	EndpointPurgeTrash
 	EndpointGet
 	EndpointAdd
 	EndpointUpdate
+	EndpointDelete
+	EndpointRestore
 )
 
 var Endpoints = router.Endpoints{
@@ -17,30 +23,65 @@ var Endpoints = router.Endpoints{
 		Verb:    "GET",
 		Path:    "/",
 		Handler: HandlerList,
-		Auth:    nil,
+		Auth:    users.Auth,
+		Params:  &HandlerListParams{},
+	},
+	EndpointListTrash: {
+		Verb:        "GET",
+		Path:        "/trash",
+		Handler:     HandlerListTrash,
+		Auth:        users.Auth,
+		Permissions: []string{users.PermissionAdmin},
+	},
+	EndpointPurgeTrash: {
+		Verb:        "DELETE",
+		Path:        "/trash",
+		Handler:     HandlerPurgeTrash,
+		Auth:        users.Auth,
+		Permissions: []string{users.PermissionAdmin},
 	},
 	EndpointGet: {
 		Verb:    "GET",
 		Path:    "/{id}",
 		Handler: HandlerGet,
-		Auth:    nil,
+		Auth:    users.Auth,
+		Params:  &HandlerGetParams{},
 	},
 	EndpointAdd: {
-		Verb:    "POST",
-		Path:    "/",
-		Handler: HandlerAdd,
-		Auth:    nil,
-		Params:  &HandlerAddParams{},
+		Verb:        "POST",
+		Path:        "/",
+		Handler:     HandlerAdd,
+		Auth:        users.Auth,
+		Params:      &HandlerAddParams{},
+		Permissions: []string{users.PermissionArticlesWrite},
 	},
 	EndpointUpdate: {
-		Verb:    "PATCH",
-		Path:    "/{id}",
-		Handler: HandlerUpdate,
-		Auth:    nil,
+		Verb:        "PATCH",
+		Path:        "/{id}",
+		Handler:     HandlerUpdate,
+		Auth:        users.Auth,
+		Params:      &HandlerUpdateParams{},
+		Permissions: []string{users.PermissionArticlesWrite},
+	},
+	EndpointDelete: {
+		Verb:        "DELETE",
+		Path:        "/{id}",
+		Handler:     HandlerDelete,
+		Auth:        users.Auth,
+		Params:      &HandlerDeleteParams{},
+		Permissions: []string{users.PermissionArticlesWrite},
+	},
+	EndpointRestore: {
+		Verb:        "POST",
+		Path:        "/{id}/restore",
+		Handler:     HandlerRestore,
+		Auth:        users.Auth,
+		Params:      &HandlerRestoreParams{},
+		Permissions: []string{users.PermissionArticlesWrite},
 	},
 }
 
 // SetRoutes is used to set all the routes of the article
-func SetRoutes(r *mux.Router) {
-	Endpoints.Activate(r)
+func SetRoutes(g *router.Group) {
+	g.Activate(Endpoints)
 }
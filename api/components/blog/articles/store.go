@@ -0,0 +1,127 @@
+package articles
+
+import (
+	"context"
+	"time"
+
+	"github.com/Nivl/api.melvin.la/api/apierror"
+	"github.com/Nivl/api.melvin.la/api/router"
+)
+
+// maxSlugRetries is the number of times Create() tries to find an unused
+// slug before giving up
+const maxSlugRetries = 10
+
+// ArticleStore represents a storage for the articles.
+// All the implementations must pass the conformance tests of the storetest
+// package
+type ArticleStore interface {
+	// Get returns the article matching the given ID or slug, and the given
+	// filters. An apierror with a 404 code is returned if nothing matches
+	Get(idOrSlug string, filters *Filters) (*Article, error)
+
+	// List returns a page of non-deleted articles
+	List(opts *ListOptions) (*ListResult, error)
+
+	// ListTrash returns all the articles in the trash, the most recently
+	// deleted first
+	ListTrash() ([]*Article, error)
+
+	// Create saves a new article. When the slug is already used, "-X" is
+	// appended to it, where X is a number
+	Create(a *Article) error
+
+	// Update saves the changes of an existing article. An apierror with a
+	// 409 code is returned if the slug is already used
+	Update(a *Article) error
+
+	// Trash soft-deletes the article by moving it to the trash
+	Trash(a *Article) error
+
+	// Restore moves the article out of the trash
+	Restore(a *Article) error
+
+	// PurgeTrash fully deletes the articles that have been in the trash for
+	// longer than the given retention period. The number of deleted
+	// articles is returned
+	PurgeTrash(retention time.Duration) (int, error)
+
+	// Delete fully deletes the article
+	Delete(a *Article) error
+}
+
+// Filters contains the filters that can be applied when getting a single
+// article. A nil field means the state is ignored
+type Filters struct {
+	Deleted   *bool
+	Published *bool
+}
+
+// storeKey is the key of the store in the context of the requests
+type storeKey struct{}
+
+// UseStore returns a middleware making the handlers use the given store
+// instead of the database of the app. It can be given to api.GetRouter()
+func UseStore(s ArticleStore) router.Middleware {
+	return router.Before(func(req *router.Request) error {
+		req.Request = req.Request.WithContext(context.WithValue(req.Request.Context(), storeKey{}, s))
+		return nil
+	})
+}
+
+// store returns the store used by the handlers. It defaults to the database
+// of the app, unless another store is set using UseStore()
+func store(req *router.Request) ArticleStore {
+	if s, ok := req.Request.Context().Value(storeKey{}).(ArticleStore); ok {
+		return s
+	}
+	return NewMongoStore(req.DB())
+}
+
+// prepareCreate checks that a new article can be saved, and sets its
+// default values
+func prepareCreate(a *Article) error {
+	if a == nil {
+		return apierror.NewServerError("article not instanced")
+	}
+
+	if err := a.setSlug(); err != nil {
+		return err
+	}
+
+	a.CreatedAt = time.Now()
+	a.setPublicationDate()
+	return nil
+}
+
+// prepareUpdate checks that an existing article can be saved. The update
+// date is not set since the slug still needs to be checked
+func prepareUpdate(a *Article) error {
+	if a == nil {
+		return apierror.NewServerError("article not instanced")
+	}
+
+	if a.ID == "" {
+		return apierror.NewServerError("cannot update a non-persisted article")
+	}
+
+	return a.setSlug()
+}
+
+// setDeletion sets the deletion state of the article
+func setDeletion(a *Article, deleted bool) error {
+	if a == nil {
+		return apierror.NewServerError("article not instanced")
+	}
+
+	if a.ID == "" {
+		return apierror.NewServerError("article has not been saved")
+	}
+
+	a.IsDeleted = deleted
+	a.DeletedAt = time.Time{}
+	if deleted {
+		a.DeletedAt = time.Now()
+	}
+	return nil
+}
@@ -0,0 +1,364 @@
+package articles
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Nivl/api.melvin.la/api/apierror"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// MemoryStore is an ArticleStore keeping the articles in memory. It follows
+// the same rules as MongoStore and is safe for concurrent use
+type MemoryStore struct {
+	sync.RWMutex
+	articles map[bson.ObjectId]*Article
+}
+
+// NewMemoryStore returns an empty store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		articles: map[bson.ObjectId]*Article{},
+	}
+}
+
+// Get implements ArticleStore
+func (s *MemoryStore) Get(idOrSlug string, filters *Filters) (*Article, error) {
+	s.RLock()
+	defer s.RUnlock()
+
+	for _, a := range s.articles {
+		// Slugs cannot be ObjectIds, so there is no way for both to collide
+		if bson.IsObjectIdHex(idOrSlug) {
+			if a.ID != bson.ObjectIdHex(idOrSlug) {
+				continue
+			}
+		} else if a.Slug != idOrSlug {
+			continue
+		}
+
+		if filters.match(a) {
+			return copyArticle(a), nil
+		}
+	}
+
+	return nil, apierror.NewNotFound("article [%s] not found", idOrSlug)
+}
+
+// List implements ArticleStore
+func (s *MemoryStore) List(opts *ListOptions) (*ListResult, error) {
+	field, desc, err := opts.normalize()
+	if err != nil {
+		return nil, err
+	}
+
+	var cursorValue interface{}
+	if opts.Cursor != nil {
+		cursorValue = cursorSortValue(field, opts.Cursor)
+	}
+
+	s.RLock()
+	arts := []*Article{}
+	for _, a := range s.articles {
+		if !opts.Filters.match(a) {
+			continue
+		}
+
+		// Drafts don't have a publication date
+		if field == "published_at" && !a.IsPublished {
+			continue
+		}
+
+		if opts.Cursor != nil && !isAfter(sortValue(field, a), a.ID, cursorValue, opts.Cursor.ID, desc) {
+			continue
+		}
+
+		arts = append(arts, copyArticle(a))
+	}
+	s.RUnlock()
+
+	sort.Slice(arts, func(i, j int) bool {
+		return isAfter(sortValue(field, arts[j]), arts[j].ID, sortValue(field, arts[i]), arts[i].ID, desc)
+	})
+
+	// We keep one more article than needed to know if there's a next page
+	if len(arts) > opts.Limit+1 {
+		arts = arts[:opts.Limit+1]
+	}
+
+	return newListResult(arts, opts, field), nil
+}
+
+// ListTrash implements ArticleStore
+func (s *MemoryStore) ListTrash() ([]*Article, error) {
+	s.RLock()
+	arts := []*Article{}
+	for _, a := range s.articles {
+		if a.IsDeleted {
+			arts = append(arts, copyArticle(a))
+		}
+	}
+	s.RUnlock()
+
+	sort.Slice(arts, func(i, j int) bool {
+		return isAfter(arts[j].DeletedAt, arts[j].ID, arts[i].DeletedAt, arts[i].ID, true)
+	})
+	return arts, nil
+}
+
+// Create implements ArticleStore
+func (s *MemoryStore) Create(a *Article) error {
+	if err := prepareCreate(a); err != nil {
+		return err
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	// Same as MongoStore, we'll add "-X" at the end of the slug when it is
+	// already used, where X is a number
+	originalSlug := a.Slug
+	for i := 0; i < maxSlugRetries; i++ {
+		a.ID = bson.NewObjectId()
+
+		if !s.slugExists(a.Slug, a.ID) {
+			s.articles[a.ID] = copyArticle(a)
+			return nil
+		}
+
+		a.Slug = fmt.Sprintf("%s-%d", originalSlug, i)
+	}
+
+	return apierror.NewConflict("slug [%s] already exists", originalSlug)
+}
+
+// Update implements ArticleStore
+func (s *MemoryStore) Update(a *Article) error {
+	if err := prepareUpdate(a); err != nil {
+		return err
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	// We don't want to append a number to the slug like Create() does, since
+	// the slug may have been explicitly set by the user
+	if s.slugExists(a.Slug, a.ID) {
+		return apierror.NewConflict("slug [%s] already exists", a.Slug)
+	}
+
+	if _, found := s.articles[a.ID]; !found {
+		return apierror.NewNotFound("article [%s] not found", a.ID.Hex())
+	}
+
+	a.UpdatedAt = time.Now()
+	a.setPublicationDate()
+
+	s.articles[a.ID] = copyArticle(a)
+	return nil
+}
+
+// Trash implements ArticleStore
+func (s *MemoryStore) Trash(a *Article) error {
+	if err := setDeletion(a, true); err != nil {
+		return err
+	}
+	return s.saveDeletion(a)
+}
+
+// Restore implements ArticleStore
+func (s *MemoryStore) Restore(a *Article) error {
+	if err := setDeletion(a, false); err != nil {
+		return err
+	}
+	return s.saveDeletion(a)
+}
+
+// saveDeletion persists the deletion state of the article. The other
+// fields are left untouched
+func (s *MemoryStore) saveDeletion(a *Article) error {
+	s.Lock()
+	defer s.Unlock()
+
+	stored, found := s.articles[a.ID]
+	if !found {
+		return apierror.NewNotFound("article [%s] not found", a.ID.Hex())
+	}
+
+	stored.IsDeleted = a.IsDeleted
+	stored.DeletedAt = toStoredTime(a.DeletedAt)
+	return nil
+}
+
+// PurgeTrash implements ArticleStore
+func (s *MemoryStore) PurgeTrash(retention time.Duration) (int, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	limit := toStoredTime(time.Now().Add(-retention))
+	count := 0
+	for id, a := range s.articles {
+		if a.IsDeleted && !a.DeletedAt.After(limit) {
+			delete(s.articles, id)
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+// Delete implements ArticleStore
+func (s *MemoryStore) Delete(a *Article) error {
+	if a == nil {
+		return apierror.NewServerError("article not instanced")
+	}
+
+	if a.ID == "" {
+		return apierror.NewServerError("article has not been saved")
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	if _, found := s.articles[a.ID]; !found {
+		return apierror.NewNotFound("article [%s] not found", a.ID.Hex())
+	}
+
+	delete(s.articles, a.ID)
+	return nil
+}
+
+// slugExists checks if an article other than the given one uses the slug.
+// The store needs to be locked
+func (s *MemoryStore) slugExists(slug string, id bson.ObjectId) bool {
+	for _, a := range s.articles {
+		if a.Slug == slug && a.ID != id {
+			return true
+		}
+	}
+	return false
+}
+
+// match checks if the article matches the filters
+func (f *Filters) match(a *Article) bool {
+	if f == nil {
+		return true
+	}
+
+	if f.Deleted != nil && a.IsDeleted != *f.Deleted {
+		return false
+	}
+
+	return f.Published == nil || a.IsPublished == *f.Published
+}
+
+// match checks if the article matches the filters. This is the
+// equivalent of query()
+func (f *ListFilters) match(a *Article) bool {
+	if a.IsDeleted {
+		return false
+	}
+
+	if f.Published != nil && a.IsPublished != *f.Published {
+		return false
+	}
+
+	if f.Tag != "" && !hasTag(a, strings.ToLower(f.Tag)) {
+		return false
+	}
+
+	if f.AuthorID != "" && a.AuthorID != f.AuthorID {
+		return false
+	}
+
+	if !f.CreatedAfter.IsZero() && a.CreatedAt.Before(toStoredTime(f.CreatedAfter)) {
+		return false
+	}
+
+	if !f.CreatedBefore.IsZero() && !a.CreatedAt.Before(toStoredTime(f.CreatedBefore)) {
+		return false
+	}
+
+	return true
+}
+
+// hasTag checks if the article has the given tag
+func hasTag(a *Article, tag string) bool {
+	for _, t := range a.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// sortValue returns the value of the given field of the article
+func sortValue(field string, a *Article) interface{} {
+	switch field {
+	case "published_at":
+		return a.PublishedAt
+	case "title":
+		return a.Title
+	}
+	return a.CreatedAt
+}
+
+// cursorSortValue returns the value of the sorted field stored in the cursor
+func cursorSortValue(field string, c *Cursor) interface{} {
+	if field == "title" {
+		return c.Text
+	}
+	return toStoredTime(c.Time)
+}
+
+// isAfter checks if the first value comes after the second one using the
+// given order. The IDs are used to break ties, like cursorQuery() does
+func isAfter(value interface{}, id bson.ObjectId, other interface{}, otherID bson.ObjectId, desc bool) bool {
+	cmp := 0
+	switch v := value.(type) {
+	case time.Time:
+		o := other.(time.Time)
+		if v.Before(o) {
+			cmp = -1
+		} else if v.After(o) {
+			cmp = 1
+		}
+	case string:
+		cmp = strings.Compare(v, other.(string))
+	}
+
+	if cmp == 0 {
+		cmp = strings.Compare(string(id), string(otherID))
+	}
+
+	if desc {
+		return cmp < 0
+	}
+	return cmp > 0
+}
+
+// copyArticle returns a copy of the article as it would be returned by
+// Mongo, so the stored articles cannot be changed from the outside
+func copyArticle(a *Article) *Article {
+	cp := *a
+	if a.Tags != nil {
+		cp.Tags = append([]string{}, a.Tags...)
+	}
+
+	cp.CreatedAt = toStoredTime(a.CreatedAt)
+	cp.UpdatedAt = toStoredTime(a.UpdatedAt)
+	cp.PublishedAt = toStoredTime(a.PublishedAt)
+	cp.DeletedAt = toStoredTime(a.DeletedAt)
+	return &cp
+}
+
+// toStoredTime returns the given time with the precision used by Mongo
+func toStoredTime(t time.Time) time.Time {
+	if t.IsZero() {
+		return t
+	}
+	return t.Truncate(time.Millisecond)
+}
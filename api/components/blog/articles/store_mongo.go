@@ -0,0 +1,233 @@
+package articles
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Nivl/api.melvin.la/api/apierror"
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// MongoStore is an ArticleStore saving the articles in Mongo
+type MongoStore struct {
+	db *mgo.Database
+}
+
+// NewMongoStore returns a store using the given database
+func NewMongoStore(db *mgo.Database) *MongoStore {
+	return &MongoStore{db: db}
+}
+
+// Get implements ArticleStore
+func (s *MongoStore) Get(idOrSlug string, filters *Filters) (*Article, error) {
+	query := bson.M{}
+	if filters != nil {
+		if filters.Deleted != nil {
+			query["is_deleted"] = *filters.Deleted
+		}
+		if filters.Published != nil {
+			query["is_published"] = *filters.Published
+		}
+	}
+
+	// Slugs cannot be ObjectIds, so there is no way for both to collide
+	if bson.IsObjectIdHex(idOrSlug) {
+		query["_id"] = bson.ObjectIdHex(idOrSlug)
+	} else {
+		query["slug"] = idOrSlug
+	}
+
+	a := &Article{}
+	if err := Query(s.db).Find(query).One(a); err != nil {
+		if err == mgo.ErrNotFound {
+			return nil, apierror.NewNotFound("article [%s] not found", idOrSlug)
+		}
+		return nil, apierror.NewServerError("%s", err.Error())
+	}
+
+	return a, nil
+}
+
+// List implements ArticleStore
+func (s *MongoStore) List(opts *ListOptions) (*ListResult, error) {
+	field, desc, err := opts.normalize()
+	if err != nil {
+		return nil, err
+	}
+
+	query := opts.Filters.query()
+
+	// Drafts don't have a publication date
+	if field == "published_at" {
+		query["is_published"] = true
+	}
+
+	if opts.Cursor != nil {
+		query = bson.M{"$and": []bson.M{query, cursorQuery(field, desc, opts.Cursor)}}
+	}
+
+	sort := []string{opts.Sort, "_id"}
+	if desc {
+		sort[1] = "-_id"
+	}
+
+	// We get one more article than needed to know if there's a next page
+	arts := []*Article{}
+	if err := Query(s.db).Find(query).Sort(sort...).Limit(opts.Limit + 1).All(&arts); err != nil {
+		return nil, apierror.NewServerError("%s", err.Error())
+	}
+
+	return newListResult(arts, opts, field), nil
+}
+
+// ListTrash implements ArticleStore
+func (s *MongoStore) ListTrash() ([]*Article, error) {
+	arts := []*Article{}
+	if err := Query(s.db).Find(bson.M{"is_deleted": true}).Sort("-deleted_at").All(&arts); err != nil {
+		return nil, apierror.NewServerError("%s", err.Error())
+	}
+	return arts, nil
+}
+
+// Create implements ArticleStore
+func (s *MongoStore) Create(a *Article) error {
+	if err := prepareCreate(a); err != nil {
+		return err
+	}
+
+	// To prevent duplicates on the slug, we'll retry the insert() up to 10 times
+	originalSlug := a.Slug
+	var err error
+	for i := 0; i < maxSlugRetries; i++ {
+		a.ID = bson.NewObjectId()
+		err = Query(s.db).Insert(a)
+
+		if err != nil {
+			// In case of duplicate we'll add "-X" at the end of the slug, where X is
+			// a number
+			a.Slug = fmt.Sprintf("%s-%d", originalSlug, i)
+
+			if mgo.IsDup(err) == false {
+				return apierror.NewServerError("%s", err.Error())
+			}
+		} else {
+			// everything went well
+			return nil
+		}
+	}
+
+	// after 10 try we just return an error
+	return apierror.NewConflict("%s", err.Error())
+}
+
+// Update implements ArticleStore
+func (s *MongoStore) Update(a *Article) error {
+	if err := prepareUpdate(a); err != nil {
+		return err
+	}
+
+	// We don't want to append a number to the slug like Create() does, since
+	// the slug may have been explicitly set by the user
+	query := bson.M{"slug": a.Slug, "_id": bson.M{"$ne": a.ID}}
+	count, err := Query(s.db).Find(query).Count()
+	if err != nil {
+		return apierror.NewServerError("%s", err.Error())
+	}
+	if count > 0 {
+		return apierror.NewConflict("slug [%s] already exists", a.Slug)
+	}
+
+	a.UpdatedAt = time.Now()
+	a.setPublicationDate()
+
+	if err := Query(s.db).UpdateId(a.ID, a); err != nil {
+		if mgo.IsDup(err) {
+			return apierror.NewConflict("slug [%s] already exists", a.Slug)
+		}
+
+		if err == mgo.ErrNotFound {
+			return apierror.NewNotFound("article [%s] not found", a.ID.Hex())
+		}
+
+		return apierror.NewServerError("%s", err.Error())
+	}
+
+	return nil
+}
+
+// Trash implements ArticleStore
+func (s *MongoStore) Trash(a *Article) error {
+	if err := setDeletion(a, true); err != nil {
+		return err
+	}
+	return s.saveDeletion(a)
+}
+
+// Restore implements ArticleStore
+func (s *MongoStore) Restore(a *Article) error {
+	if err := setDeletion(a, false); err != nil {
+		return err
+	}
+	return s.saveDeletion(a)
+}
+
+// saveDeletion persists the deletion state of the article
+func (s *MongoStore) saveDeletion(a *Article) error {
+	update := bson.M{"$set": bson.M{"is_deleted": a.IsDeleted}}
+	if a.IsDeleted {
+		update["$set"].(bson.M)["deleted_at"] = a.DeletedAt
+	} else {
+		update["$unset"] = bson.M{"deleted_at": ""}
+	}
+
+	if err := Query(s.db).UpdateId(a.ID, update); err != nil {
+		if err == mgo.ErrNotFound {
+			return apierror.NewNotFound("article [%s] not found", a.ID.Hex())
+		}
+		return apierror.NewServerError("%s", err.Error())
+	}
+
+	return nil
+}
+
+// PurgeTrash implements ArticleStore
+func (s *MongoStore) PurgeTrash(retention time.Duration) (int, error) {
+	arts := []*Article{}
+	query := bson.M{
+		"is_deleted": true,
+		"deleted_at": bson.M{"$lte": time.Now().Add(-retention)},
+	}
+
+	if err := Query(s.db).Find(query).All(&arts); err != nil {
+		return 0, apierror.NewServerError("%s", err.Error())
+	}
+
+	for i, a := range arts {
+		if err := s.Delete(a); err != nil {
+			return i, err
+		}
+	}
+
+	return len(arts), nil
+}
+
+// Delete implements ArticleStore
+func (s *MongoStore) Delete(a *Article) error {
+	if a == nil {
+		return apierror.NewServerError("article not instanced")
+	}
+
+	if a.ID == "" {
+		return apierror.NewServerError("article has not been saved")
+	}
+
+	if err := Query(s.db).RemoveId(a.ID); err != nil {
+		if err == mgo.ErrNotFound {
+			return apierror.NewNotFound("article [%s] not found", a.ID.Hex())
+		}
+		return apierror.NewServerError("%s", err.Error())
+	}
+
+	return nil
+}
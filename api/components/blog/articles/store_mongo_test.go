@@ -0,0 +1,35 @@
+package articles_test
+
+import (
+	"testing"
+
+	"github.com/Nivl/api.melvin.la/api/app"
+	"github.com/Nivl/api.melvin.la/api/app/testhelpers"
+	"github.com/Nivl/api.melvin.la/api/components/blog/articles"
+	"github.com/Nivl/api.melvin.la/api/components/blog/articles/storetest"
+)
+
+func TestMongoStore(t *testing.T) {
+	// The conformance tests expect an empty store, so we use a database
+	// that is not shared with the other tests
+	a, err := testhelpers.NewApp(func(params *app.Args) {
+		params.MongoDatabase = testApp.DB.Name + "_article_store"
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.Destroy()
+	defer a.DB.DropDatabase()
+
+	// The slug needs to be unique for Create() to find an unused one
+	if err := articles.EnsureIndexes(a.DB); err != nil {
+		t.Fatal(err)
+	}
+
+	storetest.Run(t, func(t *testing.T) articles.ArticleStore {
+		if _, err := articles.Query(a.DB).RemoveAll(nil); err != nil {
+			t.Fatal(err)
+		}
+		return articles.NewMongoStore(a.DB)
+	})
+}
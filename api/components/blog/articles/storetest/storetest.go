@@ -0,0 +1,450 @@
+// Package storetest contains the conformance tests that all the
+// implementations of articles.ArticleStore need to pass
+package storetest
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Nivl/api.melvin.la/api/apierror"
+	"github.com/Nivl/api.melvin.la/api/components/blog/articles"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// NewStoreFunc returns an empty store
+type NewStoreFunc func(t *testing.T) articles.ArticleStore
+
+var (
+	yes = true
+	no  = false
+)
+
+// Run runs the conformance tests against the stores returned by newStore.
+// Each test uses its own store
+func Run(t *testing.T, newStore NewStoreFunc) {
+	tests := []struct {
+		description string
+		test        func(*testing.T, articles.ArticleStore)
+	}{
+		{"Create", testCreate},
+		{"Create with a used slug", testCreateUsedSlug},
+		{"Create with an invalid slug", testCreateInvalidSlug},
+		{"Create with an invalid generated slug", testCreateGeneratedInvalidSlug},
+		{"Concurrent creates", testConcurrentCreates},
+		{"Get", testGet},
+		{"Update", testUpdate},
+		{"Update with a used slug", testUpdateUsedSlug},
+		{"Trash and restore", testTrashAndRestore},
+		{"Purge the trash", testPurgeTrash},
+		{"Delete", testDelete},
+		{"List with filters", testListFilters},
+		{"List sorted", testListSort},
+		{"List using cursors", testListCursor},
+		{"List with invalid options", testListInvalidOptions},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.test(t, newStore(t))
+		})
+	}
+}
+
+// create saves a new article in the store
+func create(t *testing.T, s articles.ArticleStore, a *articles.Article) *articles.Article {
+	if err := s.Create(a); err != nil {
+		t.Fatalf("failed to create the article: %s", err)
+	}
+	return a
+}
+
+// assertCode checks that err is an apierror having the given code
+func assertCode(t *testing.T, code int, err error) {
+	apiErr, ok := err.(apierror.Error)
+	if assert.True(t, ok, "expected an apierror, got %v", err) {
+		assert.Equal(t, code, apiErr.Code())
+	}
+}
+
+// ids returns the IDs of the given articles
+func ids(arts []*articles.Article) []bson.ObjectId {
+	list := make([]bson.ObjectId, len(arts))
+	for i, a := range arts {
+		list[i] = a.ID
+	}
+	return list
+}
+
+func testCreate(t *testing.T, s articles.ArticleStore) {
+	a := create(t, s, &articles.Article{Title: "My Article", IsPublished: true, Tags: []string{"go"}})
+
+	assert.True(t, a.ID.Valid())
+	assert.Equal(t, "my-article", a.Slug)
+	assert.False(t, a.CreatedAt.IsZero())
+	assert.False(t, a.PublishedAt.IsZero())
+
+	draft := create(t, s, &articles.Article{Title: "Draft", Slug: "custom-slug"})
+	assert.Equal(t, "custom-slug", draft.Slug)
+	assert.True(t, draft.PublishedAt.IsZero())
+
+	// Changing the article after its creation should not change the store
+	a.Tags[0] = "changed"
+
+	saved, err := s.Get(a.ID.Hex(), nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, a.Title, saved.Title)
+		assert.Equal(t, []string{"go"}, saved.Tags)
+		assert.WithinDuration(t, a.CreatedAt, saved.CreatedAt, time.Millisecond)
+	}
+}
+
+func testCreateUsedSlug(t *testing.T, s articles.ArticleStore) {
+	slugs := []string{"slug", "slug-0", "slug-1"}
+	for _, slug := range slugs {
+		a := create(t, s, &articles.Article{Title: "Slug"})
+		assert.Equal(t, slug, a.Slug)
+	}
+}
+
+func testCreateInvalidSlug(t *testing.T, s articles.ArticleStore) {
+	tests := []struct {
+		description string
+		slug        string
+	}{
+		{"ObjectId", bson.NewObjectId().Hex()},
+		{"Reserved", "trash"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			err := s.Create(&articles.Article{Title: "title", Slug: tc.slug})
+			assertCode(t, http.StatusBadRequest, err)
+		})
+	}
+}
+
+func testCreateGeneratedInvalidSlug(t *testing.T, s articles.ArticleStore) {
+	id := bson.NewObjectId().Hex()
+	tests := []struct {
+		description string
+		title       string
+		slug        string
+	}{
+		{"ObjectId", id, id + "-0"},
+		{"Reserved", "Trash", "trash-0"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			a := create(t, s, &articles.Article{Title: tc.title})
+			assert.Equal(t, tc.slug, a.Slug)
+		})
+	}
+}
+
+func testConcurrentCreates(t *testing.T, s articles.ArticleStore) {
+	const count = 5
+	arts := make([]*articles.Article, count)
+
+	var wg sync.WaitGroup
+	for i := 0; i < count; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			arts[i] = &articles.Article{Title: "Concurrent"}
+			assert.NoError(t, s.Create(arts[i]))
+		}(i)
+	}
+	wg.Wait()
+
+	used := map[string]bool{}
+	for _, a := range arts {
+		assert.False(t, used[a.Slug], "slug [%s] used twice", a.Slug)
+		used[a.Slug] = true
+	}
+}
+
+func testGet(t *testing.T, s articles.ArticleStore) {
+	published := create(t, s, &articles.Article{Title: "Published", IsPublished: true})
+	draft := create(t, s, &articles.Article{Title: "Draft"})
+	deleted := create(t, s, &articles.Article{Title: "Deleted", IsPublished: true, IsDeleted: true, DeletedAt: time.Now()})
+
+	tests := []struct {
+		description string
+		idOrSlug    string
+		filters     *articles.Filters
+		expected    *articles.Article
+	}{
+		{"By ID", published.ID.Hex(), nil, published},
+		{"By slug", published.Slug, nil, published},
+		{"Unknown ID", bson.NewObjectId().Hex(), nil, nil},
+		{"Unknown slug", "unknown", nil, nil},
+		{"Published", published.Slug, &articles.Filters{Deleted: &no, Published: &yes}, published},
+		{"Draft as published", draft.Slug, &articles.Filters{Published: &yes}, nil},
+		{"Draft", draft.Slug, &articles.Filters{Published: &no}, draft},
+		{"Deleted as not deleted", deleted.Slug, &articles.Filters{Deleted: &no}, nil},
+		{"Deleted", deleted.ID.Hex(), &articles.Filters{Deleted: &yes}, deleted},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			a, err := s.Get(tc.idOrSlug, tc.filters)
+			if tc.expected == nil {
+				assertCode(t, http.StatusNotFound, err)
+				return
+			}
+
+			if assert.NoError(t, err) {
+				assert.Equal(t, tc.expected.ID, a.ID)
+			}
+		})
+	}
+}
+
+func testUpdate(t *testing.T, s articles.ArticleStore) {
+	a := create(t, s, &articles.Article{Title: "Title"})
+
+	a.Title = "New Title"
+	a.Slug = ""
+	a.IsPublished = true
+	if assert.NoError(t, s.Update(a)) {
+		assert.Equal(t, "new-title", a.Slug)
+		assert.False(t, a.UpdatedAt.IsZero())
+		assert.False(t, a.PublishedAt.IsZero())
+	}
+
+	saved, err := s.Get(a.ID.Hex(), nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "New Title", saved.Title)
+		assert.Equal(t, "new-title", saved.Slug)
+		assert.True(t, saved.IsPublished)
+	}
+
+	unknown := &articles.Article{ID: bson.NewObjectId(), Title: "Unknown"}
+	assertCode(t, http.StatusNotFound, s.Update(unknown))
+
+	notSaved := &articles.Article{Title: "Not saved"}
+	assertCode(t, http.StatusInternalServerError, s.Update(notSaved))
+}
+
+func testUpdateUsedSlug(t *testing.T, s articles.ArticleStore) {
+	a := create(t, s, &articles.Article{Title: "First"})
+	other := create(t, s, &articles.Article{Title: "Second"})
+
+	a.Slug = other.Slug
+	assertCode(t, http.StatusConflict, s.Update(a))
+
+	// Keeping its own slug is fine
+	other.Title = "Still second"
+	assert.NoError(t, s.Update(other))
+}
+
+func testTrashAndRestore(t *testing.T, s articles.ArticleStore) {
+	first := create(t, s, &articles.Article{Title: "First"})
+	second := create(t, s, &articles.Article{Title: "Second"})
+	create(t, s, &articles.Article{Title: "Not deleted"})
+
+	if assert.NoError(t, s.Trash(first)) {
+		assert.True(t, first.IsDeleted)
+		assert.False(t, first.DeletedAt.IsZero())
+	}
+
+	// Makes sure both articles have a different deletion date
+	time.Sleep(5 * time.Millisecond)
+	assert.NoError(t, s.Trash(second))
+
+	trash, err := s.ListTrash()
+	if assert.NoError(t, err) {
+		assert.Equal(t, []bson.ObjectId{second.ID, first.ID}, ids(trash))
+	}
+
+	if assert.NoError(t, s.Restore(first)) {
+		assert.False(t, first.IsDeleted)
+		assert.True(t, first.DeletedAt.IsZero())
+	}
+
+	restored, err := s.Get(first.ID.Hex(), &articles.Filters{Deleted: &no})
+	if assert.NoError(t, err) {
+		assert.True(t, restored.DeletedAt.IsZero())
+	}
+
+	trash, err = s.ListTrash()
+	if assert.NoError(t, err) {
+		assert.Equal(t, []bson.ObjectId{second.ID}, ids(trash))
+	}
+
+	unknown := &articles.Article{ID: bson.NewObjectId()}
+	assertCode(t, http.StatusNotFound, s.Trash(unknown))
+	assertCode(t, http.StatusNotFound, s.Restore(unknown))
+}
+
+func testPurgeTrash(t *testing.T, s articles.ArticleStore) {
+	expired := create(t, s, &articles.Article{Title: "Expired", IsDeleted: true, DeletedAt: time.Now().Add(-48 * time.Hour)})
+	recent := create(t, s, &articles.Article{Title: "Recent", IsDeleted: true, DeletedAt: time.Now()})
+	kept := create(t, s, &articles.Article{Title: "Kept"})
+
+	count, err := s.PurgeTrash(24 * time.Hour)
+	if assert.NoError(t, err) {
+		assert.Equal(t, 1, count)
+	}
+
+	_, err = s.Get(expired.ID.Hex(), nil)
+	assertCode(t, http.StatusNotFound, err)
+
+	for _, a := range []*articles.Article{recent, kept} {
+		_, err = s.Get(a.ID.Hex(), nil)
+		assert.NoError(t, err)
+	}
+}
+
+func testDelete(t *testing.T, s articles.ArticleStore) {
+	a := create(t, s, &articles.Article{Title: "Deleted"})
+
+	assert.NoError(t, s.Delete(a))
+	_, err := s.Get(a.ID.Hex(), nil)
+	assertCode(t, http.StatusNotFound, err)
+
+	assertCode(t, http.StatusNotFound, s.Delete(a))
+
+	// The slug can be used again
+	other := create(t, s, &articles.Article{Title: "Deleted"})
+	assert.Equal(t, a.Slug, other.Slug)
+}
+
+func testListFilters(t *testing.T, s articles.ArticleStore) {
+	author := bson.NewObjectId()
+
+	old := create(t, s, &articles.Article{Title: "Old", IsPublished: true, Tags: []string{"go"}})
+	time.Sleep(5 * time.Millisecond)
+	middle := time.Now()
+	time.Sleep(5 * time.Millisecond)
+
+	byAuthor := create(t, s, &articles.Article{Title: "By author", IsPublished: true, AuthorID: author})
+	draft := create(t, s, &articles.Article{Title: "Draft", Tags: []string{"go", "mongo"}})
+	create(t, s, &articles.Article{Title: "Deleted", IsPublished: true, IsDeleted: true, DeletedAt: time.Now()})
+
+	tests := []struct {
+		description string
+		filters     articles.ListFilters
+		expected    []*articles.Article
+	}{
+		{"No filters", articles.ListFilters{}, []*articles.Article{draft, byAuthor, old}},
+		{"Published", articles.ListFilters{Published: &yes}, []*articles.Article{byAuthor, old}},
+		{"Drafts", articles.ListFilters{Published: &no}, []*articles.Article{draft}},
+		{"Tag", articles.ListFilters{Tag: "GO"}, []*articles.Article{draft, old}},
+		{"Author", articles.ListFilters{AuthorID: author}, []*articles.Article{byAuthor}},
+		{"Created after", articles.ListFilters{CreatedAfter: middle}, []*articles.Article{draft, byAuthor}},
+		{"Created before", articles.ListFilters{CreatedBefore: middle}, []*articles.Article{old}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			res, err := s.List(&articles.ListOptions{Limit: 10, Filters: tc.filters})
+			if assert.NoError(t, err) {
+				assert.Equal(t, ids(tc.expected), ids(res.Articles))
+				assert.Nil(t, res.Next)
+			}
+		})
+	}
+}
+
+func testListSort(t *testing.T, s articles.ArticleStore) {
+	b := create(t, s, &articles.Article{Title: "b", IsPublished: true})
+	a := create(t, s, &articles.Article{Title: "a"})
+	c := create(t, s, &articles.Article{Title: "c", IsPublished: true})
+
+	tests := []struct {
+		sort     string
+		expected []*articles.Article
+	}{
+		{"", []*articles.Article{c, a, b}},
+		{"created_at", []*articles.Article{b, a, c}},
+		{"title", []*articles.Article{a, b, c}},
+		{"-title", []*articles.Article{c, b, a}},
+		// Drafts are not returned when sorting on the publication date
+		{"-published_at", []*articles.Article{c, b}},
+	}
+
+	for _, tc := range tests {
+		t.Run(fmt.Sprintf("Sort [%s]", tc.sort), func(t *testing.T) {
+			res, err := s.List(&articles.ListOptions{Limit: 10, Sort: tc.sort})
+			if assert.NoError(t, err) {
+				assert.Equal(t, ids(tc.expected), ids(res.Articles))
+			}
+		})
+	}
+}
+
+func testListCursor(t *testing.T, s articles.ArticleStore) {
+	// All the articles have the same title to make sure the IDs are used to
+	// break ties
+	expected := []bson.ObjectId{}
+	for i := 0; i < 5; i++ {
+		a := create(t, s, &articles.Article{Title: "same"})
+		expected = append(expected, a.ID)
+	}
+
+	for _, sort := range []string{"title", "-created_at"} {
+		t.Run(fmt.Sprintf("Sort [%s]", sort), func(t *testing.T) {
+			found := []bson.ObjectId{}
+			opts := &articles.ListOptions{Limit: 2, Sort: sort}
+
+			for pages := 0; pages < 5; pages++ {
+				res, err := s.List(opts)
+				if !assert.NoError(t, err) {
+					return
+				}
+
+				found = append(found, ids(res.Articles)...)
+				if res.Next == nil {
+					break
+				}
+
+				// The cursor needs to survive being sent to a client
+				cursor, err := articles.DecodeCursor(res.Next.Encode())
+				if !assert.NoError(t, err) {
+					return
+				}
+				opts = &articles.ListOptions{Limit: 2, Sort: sort, Cursor: cursor}
+			}
+
+			if sort == "-created_at" {
+				for i, j := 0, len(found)-1; i < j; i, j = i+1, j-1 {
+					found[i], found[j] = found[j], found[i]
+				}
+			}
+			assert.Equal(t, expected, found)
+		})
+	}
+}
+
+func testListInvalidOptions(t *testing.T, s articles.ArticleStore) {
+	// Two articles are needed to get a cursor
+	create(t, s, &articles.Article{Title: "first"})
+	create(t, s, &articles.Article{Title: "second"})
+
+	res, err := s.List(&articles.ListOptions{Limit: 1})
+	if !assert.NoError(t, err) || !assert.NotNil(t, res.Next) {
+		return
+	}
+
+	tests := []struct {
+		description string
+		opts        *articles.ListOptions
+	}{
+		{"Unknown sort", &articles.ListOptions{Limit: 10, Sort: "content"}},
+		{"Limit too low", &articles.ListOptions{Limit: 0}},
+		{"Cursor of another sort", &articles.ListOptions{Limit: 10, Sort: "title", Cursor: res.Next}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			_, err := s.List(tc.opts)
+			assertCode(t, http.StatusBadRequest, err)
+		})
+	}
+}
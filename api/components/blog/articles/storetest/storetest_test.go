@@ -0,0 +1,14 @@
+package storetest_test
+
+import (
+	"testing"
+
+	"github.com/Nivl/api.melvin.la/api/components/blog/articles"
+	"github.com/Nivl/api.melvin.la/api/components/blog/articles/storetest"
+)
+
+func TestMemoryStore(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) articles.ArticleStore {
+		return articles.NewMemoryStore()
+	})
+}
@@ -1,8 +1,11 @@
 package blog
 
-import "github.com/Nivl/api.melvin.la/api/components/blog/articles"
+import (
+	"github.com/Nivl/api.melvin.la/api/components/blog/articles"
+	mgo "gopkg.in/mgo.v2"
+)
 
 // EnsureIndexes sets the indexes for all the documents in the blog
-func EnsureIndexes() {
-	articles.EnsureIndexes()
+func EnsureIndexes(db *mgo.Database) error {
+	return articles.EnsureIndexes(db)
 }
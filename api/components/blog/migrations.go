@@ -0,0 +1,11 @@
+package blog
+
+import (
+	"github.com/Nivl/api.melvin.la/api/components/blog/articles"
+	"github.com/Nivl/api.melvin.la/api/migration"
+)
+
+// Migrations returns the migrations of all the documents in the blog
+func Migrations() []*migration.Migration {
+	return articles.Migrations
+}
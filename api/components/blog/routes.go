@@ -2,10 +2,10 @@ package blog
 
 import (
 	"github.com/Nivl/api.melvin.la/api/components/blog/articles"
-	"github.com/gorilla/mux"
+	"github.com/Nivl/api.melvin.la/api/router"
 )
 
 // SetRoutes is used to set all the routes of the blog
-func SetRoutes(r *mux.Router) {
-	articles.SetRoutes(r.PathPrefix("/articles").Subrouter())
+func SetRoutes(g *router.Group) {
+	articles.SetRoutes(g.Group("/articles"))
 }
@@ -0,0 +1,26 @@
+package status
+
+import (
+	"github.com/Nivl/api.melvin.la/api/router"
+	mgo "gopkg.in/mgo.v2"
+)
+
+// HandlerDBStats represents an API handler to get the statistics of the pool
+// of Mongo connections. SocketsInUse getting close to PoolLimit means the
+// pool needs to be bigger
+func HandlerDBStats(req *router.Request) {
+	stats := mgo.GetStats()
+
+	req.Ok(&DBStatsPayload{
+		PoolLimit:    req.App.Params.MongoPoolLimit,
+		Clusters:     stats.Clusters,
+		MasterConns:  stats.MasterConns,
+		SlaveConns:   stats.SlaveConns,
+		SentOps:      stats.SentOps,
+		ReceivedOps:  stats.ReceivedOps,
+		ReceivedDocs: stats.ReceivedDocs,
+		SocketsAlive: stats.SocketsAlive,
+		SocketsInUse: stats.SocketsInUse,
+		SocketRefs:   stats.SocketRefs,
+	})
+}
@@ -0,0 +1,59 @@
+package status_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/Nivl/api.melvin.la/api/app/testhelpers"
+	"github.com/Nivl/api.melvin.la/api/components/status"
+	"github.com/Nivl/api.melvin.la/api/components/users"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandlerDBStats(t *testing.T) {
+	adminUser, admin := users.NewTestAuth(t, testApp.DB, users.RoleAdmin)
+	testhelpers.SaveModel(t, testApp.DB, adminUser)
+	testhelpers.SaveModel(t, testApp.DB, admin)
+
+	editorUser, editor := users.NewTestAuth(t, testApp.DB, users.RoleEditor)
+	testhelpers.SaveModel(t, testApp.DB, editorUser)
+	testhelpers.SaveModel(t, testApp.DB, editor)
+
+	defer testhelpers.PurgeModels(t)
+
+	tests := []struct {
+		description string
+		token       string
+		code        int
+	}{
+		{"No auth", "", http.StatusUnauthorized},
+		{"Not an admin", editor.Token, http.StatusForbidden},
+		{"Admin", admin.Token, http.StatusOK},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			ri := &testhelpers.RequestInfo{
+				Test:     t,
+				App:      testApp,
+				Endpoint: status.Endpoints[status.EndpointDBStats],
+				URI:      "/stats/db",
+				Token:    tc.token,
+			}
+
+			rec := testhelpers.NewRequest(ri)
+			assert.Equal(t, tc.code, rec.Code)
+
+			if rec.Code == http.StatusOK {
+				var pld status.DBStatsPayload
+				if err := json.NewDecoder(rec.Body).Decode(&pld); err != nil {
+					t.Fatal(err)
+				}
+
+				assert.Equal(t, testApp.Params.MongoPoolLimit, pld.PoolLimit)
+				assert.True(t, pld.SocketsAlive > 0)
+			}
+		})
+	}
+}
@@ -0,0 +1,8 @@
+package status
+
+import "github.com/Nivl/api.melvin.la/api/router"
+
+// HandlerHealth represents an API handler to check that the API is alive
+func HandlerHealth(req *router.Request) {
+	req.Ok(&StatusPayload{Status: "ok"})
+}
@@ -0,0 +1,29 @@
+package status_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/Nivl/api.melvin.la/api/app/testhelpers"
+	"github.com/Nivl/api.melvin.la/api/components/status"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandlerHealth(t *testing.T) {
+	ri := &testhelpers.RequestInfo{
+		Test:     t,
+		App:      testApp,
+		Endpoint: status.Endpoints[status.EndpointHealth],
+		URI:      "/health",
+	}
+
+	rec := testhelpers.NewRequest(ri)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var pld status.StatusPayload
+	if err := json.NewDecoder(rec.Body).Decode(&pld); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "ok", pld.Status)
+}
@@ -0,0 +1,30 @@
+package status
+
+import (
+	"github.com/Nivl/api.melvin.la/api/apierror"
+	"github.com/Nivl/api.melvin.la/api/router"
+)
+
+// HandlerReady represents an API handler to check that the API is ready to
+// handle requests
+func HandlerReady(req *router.Request) {
+	ctx := req.App
+
+	if !ctx.IndexesEnsured {
+		req.Error(apierror.NewServiceUnavailable("the indexes have not been ensured"))
+		return
+	}
+
+	session := ctx.Session.Copy()
+	defer session.Close()
+
+	// The error may contain the address of the database, so it is only
+	// logged
+	if err := session.Ping(); err != nil {
+		req.Logger.Errorf("could not ping the database: %s", err.Error())
+		req.Error(apierror.NewServiceUnavailable("the database is unreachable"))
+		return
+	}
+
+	req.Ok(&StatusPayload{Status: "ready"})
+}
@@ -0,0 +1,44 @@
+package status_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/Nivl/api.melvin.la/api/app/testhelpers"
+	"github.com/Nivl/api.melvin.la/api/components/status"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandlerReady(t *testing.T) {
+	tests := []struct {
+		description    string
+		indexesEnsured bool
+		code           int
+	}{
+		{"Indexes not ensured", false, http.StatusServiceUnavailable},
+		{"Ready", true, http.StatusOK},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			// Each test uses its own app, since the state of the indexes is
+			// stored in the app
+			a, err := testhelpers.NewApp()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer a.Destroy()
+			a.IndexesEnsured = tc.indexesEnsured
+
+			ri := &testhelpers.RequestInfo{
+				Test:     t,
+				App:      a,
+				Endpoint: status.Endpoints[status.EndpointReady],
+				URI:      "/ready",
+			}
+
+			rec := testhelpers.NewRequest(ri)
+			assert.Equal(t, tc.code, rec.Code)
+		})
+	}
+}
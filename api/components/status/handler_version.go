@@ -0,0 +1,22 @@
+package status
+
+import (
+	"runtime"
+	"time"
+
+	"github.com/Nivl/api.melvin.la/api/router"
+)
+
+// HandlerVersion represents an API handler to get the build information of
+// the API. The uptime is in seconds
+func HandlerVersion(req *router.Request) {
+	ctx := req.App
+
+	req.Ok(&VersionPayload{
+		Version:   ctx.Build.Version,
+		Commit:    ctx.Build.Commit,
+		BuildTime: ctx.Build.BuildTime,
+		GoVersion: runtime.Version(),
+		Uptime:    time.Since(ctx.StartedAt).Seconds(),
+	})
+}
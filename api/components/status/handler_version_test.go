@@ -0,0 +1,64 @@
+package status_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"runtime"
+	"testing"
+
+	"github.com/Nivl/api.melvin.la/api/app"
+	"github.com/Nivl/api.melvin.la/api/app/testhelpers"
+	"github.com/Nivl/api.melvin.la/api/components/status"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandlerVersion(t *testing.T) {
+	// Two apps are used to make sure they don't share their build info
+	other, err := testhelpers.NewApp()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer other.Destroy()
+
+	build := testApp.Build
+	defer func() {
+		testApp.Build = build
+	}()
+
+	testApp.Build = app.BuildInfo{Version: "1.0.0", Commit: "abcdef"}
+	other.Build = app.BuildInfo{Version: "2.0.0", Commit: "123456"}
+
+	tests := []struct {
+		description string
+		app         *app.Context
+		version     string
+		commit      string
+	}{
+		{"Default app", testApp, "1.0.0", "abcdef"},
+		{"Other app", other, "2.0.0", "123456"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			ri := &testhelpers.RequestInfo{
+				Test:     t,
+				App:      tc.app,
+				Endpoint: status.Endpoints[status.EndpointVersion],
+				URI:      "/version",
+			}
+
+			rec := testhelpers.NewRequest(ri)
+			assert.Equal(t, http.StatusOK, rec.Code)
+
+			var pld status.VersionPayload
+			if err := json.NewDecoder(rec.Body).Decode(&pld); err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, tc.version, pld.Version)
+			assert.Equal(t, tc.commit, pld.Commit)
+			assert.Equal(t, runtime.Version(), pld.GoVersion)
+			assert.True(t, pld.Uptime >= 0)
+		})
+	}
+}
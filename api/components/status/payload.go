@@ -0,0 +1,29 @@
+package status
+
+// StatusPayload represents the state of the API
+type StatusPayload struct {
+	Status string `json:"status"`
+}
+
+// VersionPayload represents the build information of the API
+type VersionPayload struct {
+	Version   string  `json:"version"`
+	Commit    string  `json:"commit"`
+	BuildTime string  `json:"build_time"`
+	GoVersion string  `json:"go_version"`
+	Uptime    float64 `json:"uptime"`
+}
+
+// DBStatsPayload represents the usage of the pool of Mongo connections
+type DBStatsPayload struct {
+	PoolLimit    int `json:"pool_limit"`
+	Clusters     int `json:"clusters"`
+	MasterConns  int `json:"master_conns"`
+	SlaveConns   int `json:"slave_conns"`
+	SentOps      int `json:"sent_ops"`
+	ReceivedOps  int `json:"received_ops"`
+	ReceivedDocs int `json:"received_docs"`
+	SocketsAlive int `json:"sockets_alive"`
+	SocketsInUse int `json:"sockets_in_use"`
+	SocketRefs   int `json:"socket_refs"`
+}
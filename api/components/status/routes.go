@@ -0,0 +1,44 @@
+package status
+
+import (
+	"github.com/Nivl/api.melvin.la/api/components/users"
+	"github.com/Nivl/api.melvin.la/api/router"
+)
+
+const (
+	EndpointHealth = iota
+	EndpointReady
+	EndpointVersion
+	EndpointDBStats
+)
+
+var Endpoints = router.Endpoints{
+	EndpointHealth: {
+		Verb:    "GET",
+		Path:    "/health",
+		Handler: HandlerHealth,
+	},
+	EndpointReady: {
+		Verb:    "GET",
+		Path:    "/ready",
+		Handler: HandlerReady,
+	},
+	EndpointVersion: {
+		Verb:    "GET",
+		Path:    "/version",
+		Handler: HandlerVersion,
+	},
+	EndpointDBStats: {
+		Verb:         "GET",
+		Path:         "/stats/db",
+		Handler:      HandlerDBStats,
+		Auth:         users.Auth,
+		AuthRequired: true,
+		Permissions:  []string{users.PermissionAdmin},
+	},
+}
+
+// SetRoutes is used to set all the routes of the status
+func SetRoutes(g *router.Group) {
+	g.Activate(Endpoints)
+}
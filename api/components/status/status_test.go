@@ -0,0 +1,24 @@
+package status_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/Nivl/api.melvin.la/api/app"
+	"github.com/Nivl/api.melvin.la/api/app/testhelpers"
+)
+
+// testApp is the app used by the tests of the package
+var testApp *app.Context
+
+func TestMain(m *testing.M) {
+	var err error
+	testApp, err = testhelpers.NewApp()
+	if err != nil {
+		panic(err)
+	}
+
+	code := m.Run()
+	testApp.Destroy()
+	os.Exit(code)
+}
@@ -0,0 +1,100 @@
+package users
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/Nivl/api.melvin.la/api/apierror"
+	"github.com/Nivl/api.melvin.la/api/logger"
+	"github.com/Nivl/api.melvin.la/api/router"
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// Auth is a router.RouteAuth that returns the user owning the token of the
+// request. The token can either be a session token, or an API key.
+// An error is returned if the token is invalid.
+func Auth(req *router.Request) (router.User, error) {
+	return Authenticate(req.DB(), req.Logger, TokenFromRequest(req.Request), req.ClientIP())
+}
+
+// Authenticate returns the user owning the given token, used from the given
+// IP. A nil user is returned if the token is empty. The non-fatal errors are
+// logged using l
+func Authenticate(db *mgo.Database, l *logger.Logger, token string, ip string) (router.User, error) {
+	if token == "" {
+		return nil, nil
+	}
+
+	if IsAPIKey(token) {
+		return authenticateAPIKey(db, l, token, ip)
+	}
+
+	s, err := GetSessionByToken(db, token)
+	if err != nil {
+		return nil, err
+	}
+
+	return getTokenOwner(db, s.UserID)
+}
+
+// authenticateAPIKey returns the user owning the given API key. The
+// permissions of the user are restricted to the scopes of the key
+func authenticateAPIKey(db *mgo.Database, l *logger.Logger, key string, ip string) (router.User, error) {
+	k, err := GetAPIKeyByKey(db, key)
+	if err != nil {
+		return nil, err
+	}
+
+	u, err := getTokenOwner(db, k.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Failing to track the usage of a key should not prevent it from being
+	// used
+	if err := k.Touch(db, ip); err != nil {
+		l.Errorf("could not update the usage of the api key %s: %s", k.ID.Hex(), err.Error())
+	}
+
+	return &ScopedUser{User: u, Key: k}, nil
+}
+
+// getTokenOwner returns the user having the given ID. An apierror with a 401
+// code is returned if the user does not exist anymore
+func getTokenOwner(db *mgo.Database, id bson.ObjectId) (*User, error) {
+	u, err := GetByID(db, id)
+	if err != nil {
+		if apiErr, ok := err.(apierror.Error); ok && apiErr.Code() == http.StatusNotFound {
+			return nil, apierror.NewUnauthorized("invalid or expired token")
+		}
+		return nil, err
+	}
+
+	return u, nil
+}
+
+// ScopedUser represents a user authenticated using an API key.
+// Its permissions are restricted to the scopes of the key
+type ScopedUser struct {
+	*User
+	Key *APIKey
+}
+
+// HasPermission checks if both the user and the key have the given permission.
+// This is needed to implement router.User
+func (u *ScopedUser) HasPermission(permission string) bool {
+	return u.Key.HasScope(permission) && u.User.HasPermission(permission)
+}
+
+// TokenFromRequest returns the token sent in the Authorization header of the
+// request using the Bearer scheme
+func TokenFromRequest(req *http.Request) string {
+	header := strings.TrimSpace(req.Header.Get("Authorization"))
+	parts := strings.SplitN(header, " ", 2)
+	if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
+		return ""
+	}
+
+	return strings.TrimSpace(parts[1])
+}
@@ -0,0 +1,151 @@
+package users
+
+import (
+	"time"
+
+	"github.com/Nivl/api.melvin.la/api/apierror"
+	"github.com/Nivl/api.melvin.la/api/router"
+)
+
+// sessionUser returns the user of the request, making sure it has been
+// authenticated using a session. This prevents an API key from being used
+// to create keys with more scopes than itself
+func sessionUser(req *router.Request) (*User, error) {
+	u, ok := req.User.(*User)
+	if !ok {
+		return nil, apierror.NewForbidden("api keys can only be managed using a session")
+	}
+	return u, nil
+}
+
+// HandlerListAPIKeys represents an API handler to list the API keys of the
+// current user
+func HandlerListAPIKeys(req *router.Request) {
+	u, err := sessionUser(req)
+	if err != nil {
+		req.Error(err)
+		return
+	}
+
+	keys, err := ListAPIKeys(req.DB(), u.ID)
+	if err != nil {
+		req.Error(err)
+		return
+	}
+
+	req.Ok(NewAPIKeyPayloads(keys))
+}
+
+type HandlerAddAPIKeyParams struct {
+	Name      string     `from:"form" json:"name,omitempty" params:"required,trim,max_len=100"`
+	Scopes    []string   `from:"form" json:"scopes,omitempty" params:"trim"`
+	ExpiresAt *time.Time `from:"form" json:"expires_at,omitempty" params:"trim"`
+}
+
+// HandlerAddAPIKey represents an API handler to create a new API key for the
+// current user
+func HandlerAddAPIKey(req *router.Request) {
+	params, ok := req.Params.(*HandlerAddAPIKeyParams)
+	if !ok {
+		req.Error(apierror.NewServerError("Couldn't cast params"))
+		return
+	}
+
+	u, err := sessionUser(req)
+	if err != nil {
+		req.Error(err)
+		return
+	}
+
+	k := &APIKey{
+		Name:   params.Name,
+		Scopes: params.Scopes,
+	}
+
+	if params.ExpiresAt != nil {
+		if params.ExpiresAt.Before(time.Now()) {
+			req.Error(apierror.NewInvalidParam("expires_at", "parameter [expires_at] cannot be in the past"))
+			return
+		}
+		k.ExpiresAt = *params.ExpiresAt
+	}
+
+	if err := k.Create(req.DB(), u); err != nil {
+		req.Error(err)
+		return
+	}
+
+	req.Created(NewAPIKeyPayload(k))
+}
+
+type HandlerUpdateAPIKeyParams struct {
+	ID   string `from:"url" json:"id" params:"required,trim"`
+	Name string `from:"form" json:"name,omitempty" params:"required,trim,max_len=100"`
+}
+
+// HandlerUpdateAPIKey represents an API handler to rename an API key of the
+// current user
+func HandlerUpdateAPIKey(req *router.Request) {
+	params, ok := req.Params.(*HandlerUpdateAPIKeyParams)
+	if !ok {
+		req.Error(apierror.NewServerError("Couldn't cast params"))
+		return
+	}
+
+	u, err := sessionUser(req)
+	if err != nil {
+		req.Error(err)
+		return
+	}
+
+	k, err := GetAPIKey(req.DB(), u.ID, params.ID)
+	if err != nil {
+		req.Error(err)
+		return
+	}
+
+	if err := k.Rename(req.DB(), params.Name); err != nil {
+		req.Error(err)
+		return
+	}
+
+	req.Ok(NewAPIKeyPayload(k))
+}
+
+type HandlerRevokeAPIKeyParams struct {
+	ID string `from:"url" json:"id" params:"required,trim"`
+}
+
+// HandlerRevokeAPIKey represents an API handler to revoke an API key of the
+// current user
+func HandlerRevokeAPIKey(req *router.Request) {
+	params, ok := req.Params.(*HandlerRevokeAPIKeyParams)
+	if !ok {
+		req.Error(apierror.NewServerError("Couldn't cast params"))
+		return
+	}
+
+	u, err := sessionUser(req)
+	if err != nil {
+		req.Error(err)
+		return
+	}
+
+	k, err := GetAPIKey(req.DB(), u.ID, params.ID)
+	if err != nil {
+		req.Error(err)
+		return
+	}
+
+	if !k.RevokedAt.IsZero() {
+		req.NoContent()
+		return
+	}
+
+	if err := k.Revoke(req.DB()); err != nil {
+		req.Error(err)
+		return
+	}
+
+	req.NoContent()
+}
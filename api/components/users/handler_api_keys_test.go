@@ -0,0 +1,174 @@
+package users_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Nivl/api.melvin.la/api/app/helpers"
+	"github.com/Nivl/api.melvin.la/api/app/testhelpers"
+	"github.com/Nivl/api.melvin.la/api/components/users"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/mgo.v2/bson"
+)
+
+func TestHandlerAddAPIKey(t *testing.T) {
+	u, s := users.NewTestAuth(t, testApp.DB, users.RoleAuthor)
+	testhelpers.SaveModel(t, testApp.DB, u)
+	testhelpers.SaveModel(t, testApp.DB, s)
+
+	key := users.NewTestAPIKey(t, testApp.DB, u, users.PermissionArticlesWrite)
+	testhelpers.SaveModel(t, testApp.DB, key)
+
+	defer testhelpers.PurgeModels(t)
+
+	tomorrow := time.Now().Add(24 * time.Hour)
+	yesterday := time.Now().Add(-24 * time.Hour)
+
+	tests := []struct {
+		description string
+		params      *users.HandlerAddAPIKeyParams
+		token       string
+		code        int
+	}{
+		{"No auth", &users.HandlerAddAPIKeyParams{Name: "key"}, "", http.StatusUnauthorized},
+		{"Using an API key", &users.HandlerAddAPIKeyParams{Name: "key"}, key.Key, http.StatusForbidden},
+		{"No name", &users.HandlerAddAPIKeyParams{}, s.Token, http.StatusBadRequest},
+		{"Unknown scope", &users.HandlerAddAPIKeyParams{Name: "key", Scopes: []string{"nope"}}, s.Token, http.StatusBadRequest},
+		{"Scope not owned", &users.HandlerAddAPIKeyParams{Name: "key", Scopes: []string{users.PermissionAdmin}}, s.Token, http.StatusForbidden},
+		{"Past expiration", &users.HandlerAddAPIKeyParams{Name: "key", ExpiresAt: &yesterday}, s.Token, http.StatusBadRequest},
+		{"No scopes", &users.HandlerAddAPIKeyParams{Name: "key"}, s.Token, http.StatusCreated},
+		{"All params", &users.HandlerAddAPIKeyParams{Name: "key", Scopes: []string{users.PermissionArticlesWrite}, ExpiresAt: &tomorrow}, s.Token, http.StatusCreated},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			rec := callAPIKeyEndpoint(t, users.EndpointAddAPIKey, "/users/api-keys", tc.params, tc.token)
+			assert.Equal(t, tc.code, rec.Code)
+
+			if rec.Code == http.StatusCreated {
+				var pld users.APIKeyExportable
+				if err := json.NewDecoder(rec.Body).Decode(&pld); err != nil {
+					t.Fatal(err)
+				}
+
+				assert.True(t, users.IsAPIKey(pld.Key))
+				assert.Equal(t, pld.Prefix, pld.Key[:len(pld.Prefix)])
+				assert.Equal(t, len(tc.params.Scopes), len(pld.Scopes))
+				if tc.params.ExpiresAt != nil {
+					assert.Equal(t, helpers.GetDateForJSON(*tc.params.ExpiresAt), pld.ExpiresAt)
+				} else {
+					assert.Empty(t, pld.ExpiresAt)
+				}
+
+				k, err := users.GetAPIKey(testApp.DB, u.ID, pld.ID)
+				if assert.NoError(t, err) {
+					if err := k.FullyDelete(testApp.DB); err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+		})
+	}
+
+	// A date that cannot be parsed can't be sent using the params struct
+	t.Run("Invalid expiration", func(t *testing.T) {
+		params := map[string]string{"name": "key", "expires_at": "tomorrow"}
+		rec := callAPIKeyEndpoint(t, users.EndpointAddAPIKey, "/users/api-keys", params, s.Token)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Unknown field", func(t *testing.T) {
+		params := map[string]string{"name": "key", "user_id": u.ID.Hex()}
+		rec := callAPIKeyEndpoint(t, users.EndpointAddAPIKey, "/users/api-keys", params, s.Token)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Objects as scopes", func(t *testing.T) {
+		params := map[string]interface{}{"name": "key", "scopes": []interface{}{map[string]string{}}}
+		rec := callAPIKeyEndpoint(t, users.EndpointAddAPIKey, "/users/api-keys", params, s.Token)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestHandlerListAPIKeys(t *testing.T) {
+	u, s := users.NewTestAuth(t, testApp.DB)
+	testhelpers.SaveModel(t, testApp.DB, u)
+	testhelpers.SaveModel(t, testApp.DB, s)
+
+	for i := 0; i < 3; i++ {
+		testhelpers.SaveModel(t, testApp.DB, users.NewTestAPIKey(t, testApp.DB, u))
+	}
+
+	other := users.NewTestUser(t, testApp.DB, nil)
+	testhelpers.SaveModel(t, testApp.DB, other)
+	testhelpers.SaveModel(t, testApp.DB, users.NewTestAPIKey(t, testApp.DB, other))
+
+	defer testhelpers.PurgeModels(t)
+
+	rec := callAPIKeyEndpoint(t, users.EndpointListAPIKeys, "/users/api-keys", nil, s.Token)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var pld []*users.APIKeyExportable
+	if err := json.NewDecoder(rec.Body).Decode(&pld); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, 3, len(pld))
+	for _, k := range pld {
+		assert.Empty(t, k.Key)
+		assert.NotEmpty(t, k.Prefix)
+	}
+}
+
+func TestHandlerRevokeAPIKey(t *testing.T) {
+	u, s := users.NewTestAuth(t, testApp.DB)
+	testhelpers.SaveModel(t, testApp.DB, u)
+	testhelpers.SaveModel(t, testApp.DB, s)
+
+	key := users.NewTestAPIKey(t, testApp.DB, u)
+	testhelpers.SaveModel(t, testApp.DB, key)
+
+	other := users.NewTestUser(t, testApp.DB, nil)
+	testhelpers.SaveModel(t, testApp.DB, other)
+	otherKey := users.NewTestAPIKey(t, testApp.DB, other)
+	testhelpers.SaveModel(t, testApp.DB, otherKey)
+
+	defer testhelpers.PurgeModels(t)
+
+	tests := []struct {
+		description string
+		id          string
+		code        int
+	}{
+		{"Unknown key", bson.NewObjectId().Hex(), http.StatusNotFound},
+		{"Key of someone else", otherKey.ID.Hex(), http.StatusNotFound},
+		{"Valid key", key.ID.Hex(), http.StatusNoContent},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			rec := callAPIKeyEndpoint(t, users.EndpointRevokeAPIKey, "/users/api-keys/"+tc.id, nil, s.Token)
+			assert.Equal(t, tc.code, rec.Code)
+		})
+	}
+
+	// A revoked key cannot be used anymore
+	_, err := users.GetAPIKeyByKey(testApp.DB, key.Key)
+	assert.Error(t, err)
+}
+
+func callAPIKeyEndpoint(t *testing.T, endpoint int, uri string, params interface{}, token string) *httptest.ResponseRecorder {
+	ri := &testhelpers.RequestInfo{
+		Test:     t,
+		App:      testApp,
+		Endpoint: users.Endpoints[endpoint],
+		URI:      uri,
+		Params:   params,
+		Token:    token,
+	}
+
+	return testhelpers.NewRequest(ri)
+}
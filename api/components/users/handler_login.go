@@ -0,0 +1,56 @@
+package users
+
+import (
+	"net/http"
+
+	"github.com/Nivl/api.melvin.la/api/apierror"
+	"github.com/Nivl/api.melvin.la/api/router"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// dummyPasswordHash is a bcrypt hash using the same cost as the passwords of
+// the users. It is checked when the email is unknown, for the request to take
+// as long as for a registered email
+var dummyPasswordHash = []byte("$2a$10$euJdeSpKcQQq89jwT2JWVeM2dR12kpnlrLD0WXwY/wapncR.MVsOW")
+
+type HandlerLoginParams struct {
+	Email    string `from:"form" json:"email,omitempty" params:"required,trim"`
+	Password string `from:"form" json:"password,omitempty" params:"required,sensitive"`
+}
+
+// HandlerLogin represents an API handler to create a new user session
+func HandlerLogin(req *router.Request) {
+	params, ok := req.Params.(*HandlerLoginParams)
+	if !ok {
+		req.Error(apierror.NewServerError("Couldn't cast params"))
+		return
+	}
+
+	// We use the same error, and check a password, for all the failures to
+	// not leak which emails are registered
+	badCredentials := apierror.NewUnauthorized("bad email/password")
+
+	u, err := GetByEmail(req.DB(), params.Email)
+	if err != nil {
+		if apiErr, ok := err.(apierror.Error); ok && apiErr.Code() != http.StatusNotFound {
+			req.Error(err)
+			return
+		}
+		bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(params.Password))
+		req.Error(badCredentials)
+		return
+	}
+
+	if !u.IsPasswordValid(params.Password) {
+		req.Error(badCredentials)
+		return
+	}
+
+	s, err := NewSession(req.DB(), u, req.App.Params.SessionDuration)
+	if err != nil {
+		req.Error(err)
+		return
+	}
+
+	req.Created(NewSessionPayload(s, u))
+}
@@ -0,0 +1,68 @@
+package users_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Nivl/api.melvin.la/api/app/testhelpers"
+	"github.com/Nivl/api.melvin.la/api/components/users"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandlerLogin(t *testing.T) {
+	u := users.NewTestUser(t, testApp.DB, nil)
+	testhelpers.SaveModel(t, testApp.DB, u)
+	defer testhelpers.PurgeModels(t)
+
+	tests := []struct {
+		description string
+		params      *users.HandlerLoginParams
+		code        int
+	}{
+		{"No params", &users.HandlerLoginParams{}, http.StatusBadRequest},
+		{"No password", &users.HandlerLoginParams{Email: u.Email}, http.StatusBadRequest},
+		{"Unknown email", &users.HandlerLoginParams{Email: "unknown@domain.tld", Password: u.Email}, http.StatusUnauthorized},
+		{"Wrong password", &users.HandlerLoginParams{Email: u.Email, Password: "wrong"}, http.StatusUnauthorized},
+		{"Valid credentials", &users.HandlerLoginParams{Email: u.Email, Password: u.Email}, http.StatusCreated},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			rec := callHandlerLogin(t, tc.params)
+			assert.Equal(t, tc.code, rec.Code)
+
+			if rec.Code == http.StatusCreated {
+				var pld users.SessionExportable
+				if err := json.NewDecoder(rec.Body).Decode(&pld); err != nil {
+					t.Fatal(err)
+				}
+
+				assert.NotEmpty(t, pld.Token)
+				assert.NotEmpty(t, pld.ExpiresAt)
+				assert.Equal(t, u.ID.Hex(), pld.User.ID)
+
+				s, err := users.GetSessionByToken(testApp.DB, pld.Token)
+				if assert.NoError(t, err) {
+					assert.Equal(t, u.ID, s.UserID)
+					if err := s.FullyDelete(testApp.DB); err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+		})
+	}
+}
+
+func callHandlerLogin(t *testing.T, params *users.HandlerLoginParams) *httptest.ResponseRecorder {
+	ri := &testhelpers.RequestInfo{
+		Test:     t,
+		App:      testApp,
+		Endpoint: users.Endpoints[users.EndpointLogin],
+		URI:      "/users/sessions",
+		Params:   params,
+	}
+
+	return testhelpers.NewRequest(ri)
+}
@@ -0,0 +1,29 @@
+package users
+
+import (
+	"github.com/Nivl/api.melvin.la/api/apierror"
+	"github.com/Nivl/api.melvin.la/api/router"
+)
+
+// HandlerLogout represents an API handler to remove the session used to make
+// the request. API keys have no session, and are revoked using their own
+// endpoint
+func HandlerLogout(req *router.Request) {
+	if _, ok := req.User.(*ScopedUser); ok {
+		req.Error(apierror.NewBadRequest("API keys have no session, revoke the key instead"))
+		return
+	}
+
+	s, err := GetSessionByToken(req.DB(), TokenFromRequest(req.Request))
+	if err != nil {
+		req.Error(err)
+		return
+	}
+
+	if err := s.FullyDelete(req.DB()); err != nil {
+		req.Error(err)
+		return
+	}
+
+	req.NoContent()
+}
@@ -0,0 +1,51 @@
+package users_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Nivl/api.melvin.la/api/app/testhelpers"
+	"github.com/Nivl/api.melvin.la/api/components/users"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandlerLogout(t *testing.T) {
+	u, s := users.NewTestAuth(t, testApp.DB)
+	testhelpers.SaveModel(t, testApp.DB, u)
+
+	key := users.NewTestAPIKey(t, testApp.DB, u)
+	testhelpers.SaveModel(t, testApp.DB, key)
+	defer testhelpers.PurgeModels(t)
+
+	tests := []struct {
+		description string
+		token       string
+		code        int
+	}{
+		{"No auth", "", http.StatusUnauthorized},
+		{"Invalid token", "invalid", http.StatusUnauthorized},
+		{"API key", key.Key, http.StatusBadRequest},
+		{"Valid token", s.Token, http.StatusNoContent},
+		{"Token already used", s.Token, http.StatusUnauthorized},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			rec := callHandlerLogout(t, tc.token)
+			assert.Equal(t, tc.code, rec.Code)
+		})
+	}
+}
+
+func callHandlerLogout(t *testing.T, token string) *httptest.ResponseRecorder {
+	ri := &testhelpers.RequestInfo{
+		Test:     t,
+		App:      testApp,
+		Endpoint: users.Endpoints[users.EndpointLogout],
+		URI:      "/users/sessions",
+		Token:    token,
+	}
+
+	return testhelpers.NewRequest(ri)
+}
@@ -0,0 +1,242 @@
+package users
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Nivl/api.melvin.la/api/apierror"
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// APIKeyPrefix is the string all the API keys start with. It is used to tell
+// apart API keys and session tokens
+const APIKeyPrefix = "mla_"
+
+// apiKeyVisibleSize is the number of characters of a key that are saved in
+// clear and returned by the API, so users can identify their keys
+const apiKeyVisibleSize = len(APIKeyPrefix) + 8
+
+// QueryAPIKeys returns the collection of the API keys in the given
+// database
+func QueryAPIKeys(db *mgo.Database) *mgo.Collection {
+	return db.C("api_key")
+}
+
+// APIKey is a structure representing a personal API key that can be saved
+// in the database
+type APIKey struct {
+	ID         bson.ObjectId `bson:"_id"`
+	UserID     bson.ObjectId `bson:"user_id"`
+	Name       string        `bson:"name"`
+	Prefix     string        `bson:"prefix"`
+	KeyHash    string        `bson:"key_hash"`
+	Scopes     []string      `bson:"scopes"`
+	CreatedAt  time.Time     `bson:"created_at"`
+	ExpiresAt  time.Time     `bson:"expires_at,omitempty"`
+	RevokedAt  time.Time     `bson:"revoked_at,omitempty"`
+	LastUsedAt time.Time     `bson:"last_used_at,omitempty"`
+	LastUsedIP string        `bson:"last_used_ip,omitempty"`
+
+	// Key contains the clear version of the key. It is only available
+	// when the key is created since only its hash is saved
+	Key string `bson:"-"`
+}
+
+// IsAPIKey checks if the given token is an API key
+func IsAPIKey(token string) bool {
+	return strings.HasPrefix(token, APIKeyPrefix)
+}
+
+// GetAPIKey returns the API key of the given user matching the given ID.
+// An apierror with a 404 code is returned if nothing matches.
+func GetAPIKey(db *mgo.Database, userID bson.ObjectId, id string) (*APIKey, error) {
+	if !bson.IsObjectIdHex(id) {
+		return nil, apierror.NewNotFound("api key [%s] not found", id)
+	}
+
+	k := &APIKey{}
+	query := bson.M{"_id": bson.ObjectIdHex(id), "user_id": userID}
+	if err := QueryAPIKeys(db).Find(query).One(k); err != nil {
+		if err == mgo.ErrNotFound {
+			return nil, apierror.NewNotFound("api key [%s] not found", id)
+		}
+		return nil, apierror.NewServerError("%s", err.Error())
+	}
+
+	return k, nil
+}
+
+// GetAPIKeyByKey returns the usable API key matching the given key.
+// An apierror with a 401 code is returned if nothing matches, or if the key
+// has been revoked or is expired
+func GetAPIKeyByKey(db *mgo.Database, key string) (*APIKey, error) {
+	k := &APIKey{}
+	if err := QueryAPIKeys(db).Find(bson.M{"key_hash": hashToken(key)}).One(k); err != nil {
+		if err == mgo.ErrNotFound {
+			return nil, apierror.NewUnauthorized("invalid api key")
+		}
+		return nil, apierror.NewServerError("%s", err.Error())
+	}
+
+	if !k.IsUsable() {
+		return nil, apierror.NewUnauthorized("api key revoked or expired")
+	}
+
+	return k, nil
+}
+
+// ListAPIKeys returns all the API keys of the given user, the most recent
+// first
+func ListAPIKeys(db *mgo.Database, userID bson.ObjectId) ([]*APIKey, error) {
+	keys := []*APIKey{}
+	if err := QueryAPIKeys(db).Find(bson.M{"user_id": userID}).Sort("-created_at").All(&keys); err != nil {
+		return nil, apierror.NewServerError("%s", err.Error())
+	}
+	return keys, nil
+}
+
+// IsUsable checks if the key has neither been revoked nor is expired
+func (k *APIKey) IsUsable() bool {
+	if !k.RevokedAt.IsZero() {
+		return false
+	}
+
+	return k.ExpiresAt.IsZero() || k.ExpiresAt.After(time.Now())
+}
+
+// HasScope checks if the key has been granted the given permission
+func (k *APIKey) HasScope(permission string) bool {
+	for _, scope := range k.Scopes {
+		if scope == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// Create generates a new key for the given user and saves it.
+// The clear key will be available in k.Key
+func (k *APIKey) Create(db *mgo.Database, u *User) error {
+	if k == nil {
+		return apierror.NewServerError("api key not instanced")
+	}
+
+	if u == nil || u.ID == "" {
+		return apierror.NewServerError("cannot create an api key for a non-persisted user")
+	}
+
+	if err := k.validate(); err != nil {
+		return err
+	}
+
+	for _, scope := range k.Scopes {
+		if !u.HasPermission(scope) {
+			return apierror.NewForbidden("cannot grant the scope [%s] without having the permission", scope)
+		}
+	}
+
+	token, err := newToken()
+	if err != nil {
+		return apierror.NewServerError("%s", err.Error())
+	}
+
+	k.ID = bson.NewObjectId()
+	k.UserID = u.ID
+	k.Key = APIKeyPrefix + token
+	k.Prefix = k.Key[:apiKeyVisibleSize]
+	k.KeyHash = hashToken(k.Key)
+	k.CreatedAt = time.Now()
+
+	if err := QueryAPIKeys(db).Insert(k); err != nil {
+		k.ID = ""
+		return apierror.NewServerError("%s", err.Error())
+	}
+
+	return nil
+}
+
+// Rename changes the name of the key
+func (k *APIKey) Rename(db *mgo.Database, name string) error {
+	k.Name = name
+	if err := k.validate(); err != nil {
+		return err
+	}
+
+	return k.set(db, bson.M{"name": k.Name})
+}
+
+// Revoke makes the key unusable
+func (k *APIKey) Revoke(db *mgo.Database) error {
+	k.RevokedAt = time.Now()
+	return k.set(db, bson.M{"revoked_at": k.RevokedAt})
+}
+
+// Touch records that the key has just been used from the given IP
+func (k *APIKey) Touch(db *mgo.Database, ip string) error {
+	k.LastUsedAt = time.Now()
+	k.LastUsedIP = ip
+	return k.set(db, bson.M{"last_used_at": k.LastUsedAt, "last_used_ip": k.LastUsedIP})
+}
+
+// set updates the given fields of the key
+func (k *APIKey) set(db *mgo.Database, fields bson.M) error {
+	if k == nil {
+		return apierror.NewServerError("api key not instanced")
+	}
+
+	if k.ID == "" {
+		return apierror.NewServerError("api key has not been saved")
+	}
+
+	if err := QueryAPIKeys(db).UpdateId(k.ID, bson.M{"$set": fields}); err != nil {
+		if err == mgo.ErrNotFound {
+			return apierror.NewNotFound("api key [%s] not found", k.ID.Hex())
+		}
+		return apierror.NewServerError("%s", err.Error())
+	}
+
+	return nil
+}
+
+func (k *APIKey) validate() error {
+	k.Name = strings.TrimSpace(k.Name)
+	if k.Name == "" {
+		return apierror.NewInvalidParam("name", "name cannot be empty")
+	}
+
+	for _, scope := range k.Scopes {
+		if !IsValidPermission(scope) {
+			return apierror.NewInvalidParam("scopes", "scope [%s] does not exist", scope)
+		}
+	}
+
+	return nil
+}
+
+func (k *APIKey) FullyDelete(db *mgo.Database) error {
+	if k == nil {
+		return errors.New("api key not instanced")
+	}
+
+	if k.ID == "" {
+		return errors.New("api key has not been saved")
+	}
+
+	return QueryAPIKeys(db).RemoveId(k.ID)
+}
+
+// NewTestAPIKey creates and saves a new API key for the given user
+func NewTestAPIKey(t *testing.T, db *mgo.Database, u *User, scopes ...string) *APIKey {
+	k := &APIKey{
+		Name:   "test key",
+		Scopes: scopes,
+	}
+
+	if err := k.Create(db, u); err != nil {
+		t.Fatalf("failed to create the api key: %s", err)
+	}
+	return k
+}
@@ -0,0 +1,117 @@
+package users
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Nivl/api.melvin.la/api/apierror"
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// QuerySessions returns the collection of the sessions in the given
+// database
+func QuerySessions(db *mgo.Database) *mgo.Collection {
+	return db.C("session")
+}
+
+// Session is a structure representing a user session that can be saved in
+// the database
+type Session struct {
+	ID        bson.ObjectId `bson:"_id"`
+	TokenHash string        `bson:"token_hash"`
+	UserID    bson.ObjectId `bson:"user_id"`
+	CreatedAt time.Time     `bson:"created_at"`
+	ExpiresAt time.Time     `bson:"expires_at"`
+
+	// Token contains the clear version of the token. It is only available
+	// when the session is created since only its hash is saved
+	Token string `bson:"-"`
+}
+
+// NewSession creates and saves a new session for the given user
+func NewSession(db *mgo.Database, u *User, duration time.Duration) (*Session, error) {
+	if u == nil || u.ID == "" {
+		return nil, apierror.NewServerError("cannot create a session for a non-persisted user")
+	}
+
+	token, err := newToken()
+	if err != nil {
+		return nil, apierror.NewServerError("%s", err.Error())
+	}
+
+	now := time.Now()
+	s := &Session{
+		ID:        bson.NewObjectId(),
+		Token:     token,
+		TokenHash: hashToken(token),
+		UserID:    u.ID,
+		CreatedAt: now,
+		ExpiresAt: now.Add(duration),
+	}
+
+	if err := QuerySessions(db).Insert(s); err != nil {
+		return nil, apierror.NewServerError("%s", err.Error())
+	}
+
+	return s, nil
+}
+
+// GetSessionByToken returns the non-expired session matching the given
+// token. An apierror with a 401 code is returned if nothing matches.
+func GetSessionByToken(db *mgo.Database, token string) (*Session, error) {
+	query := bson.M{
+		"token_hash": hashToken(token),
+		"expires_at": bson.M{"$gt": time.Now()},
+	}
+
+	s := &Session{}
+	if err := QuerySessions(db).Find(query).One(s); err != nil {
+		if err == mgo.ErrNotFound {
+			return nil, apierror.NewUnauthorized("invalid or expired token")
+		}
+		return nil, apierror.NewServerError("%s", err.Error())
+	}
+
+	return s, nil
+}
+
+// DeleteSessions removes all the sessions of the given user, which logs
+// the user out everywhere. The number of deleted sessions is returned
+func DeleteSessions(db *mgo.Database, u *User) (int, error) {
+	if u == nil || u.ID == "" {
+		return 0, apierror.NewServerError("cannot delete the sessions of a non-persisted user")
+	}
+
+	info, err := QuerySessions(db).RemoveAll(bson.M{"user_id": u.ID})
+	if err != nil {
+		return 0, apierror.NewServerError("%s", err.Error())
+	}
+
+	return info.Removed, nil
+}
+
+func (s *Session) FullyDelete(db *mgo.Database) error {
+	if s == nil {
+		return errors.New("session not instanced")
+	}
+
+	if s.ID == "" {
+		return errors.New("session has not been saved")
+	}
+
+	return QuerySessions(db).RemoveId(s.ID)
+}
+
+// NewTestAuth creates and saves a new user having the given roles, and a
+// session attached to it
+func NewTestAuth(t *testing.T, db *mgo.Database, roles ...string) (*User, *Session) {
+	u := NewTestUser(t, db, &User{Roles: roles})
+
+	s, err := NewSession(db, u, time.Hour)
+	if err != nil {
+		t.Fatalf("failed to create the session: %s", err)
+	}
+	return u, s
+}
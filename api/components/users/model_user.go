@@ -0,0 +1,239 @@
+package users
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Nivl/api.melvin.la/api/apierror"
+	"github.com/dchest/uniuri"
+	"golang.org/x/crypto/bcrypt"
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// Query returns the collection of the users in the given database
+func Query(db *mgo.Database) *mgo.Collection {
+	return db.C("user")
+}
+
+// User is a structure representing a user that can be saved in the database
+type User struct {
+	ID        bson.ObjectId `bson:"_id"`
+	Name      string        `bson:"name"`
+	Email     string        `bson:"email"`
+	Password  string        `bson:"password"`
+	Roles     []string      `bson:"roles"`
+	CreatedAt time.Time     `bson:"created_at"`
+}
+
+// GetByID returns the user matching the given ID.
+// An apierror with a 404 code is returned if nothing matches.
+func GetByID(db *mgo.Database, id bson.ObjectId) (*User, error) {
+	return getOne(db, bson.M{"_id": id})
+}
+
+// GetByEmail returns the user matching the given email.
+// An apierror with a 404 code is returned if nothing matches.
+func GetByEmail(db *mgo.Database, email string) (*User, error) {
+	return getOne(db, bson.M{"email": normalizeEmail(email)})
+}
+
+func getOne(db *mgo.Database, query bson.M) (*User, error) {
+	u := &User{}
+	if err := Query(db).Find(query).One(u); err != nil {
+		if err == mgo.ErrNotFound {
+			return nil, apierror.NewNotFound("user not found")
+		}
+		return nil, apierror.NewServerError("%s", err.Error())
+	}
+
+	return u, nil
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// UserID returns the ID of the user as a string.
+// This is needed to implement router.User
+func (u *User) UserID() string {
+	return u.ID.Hex()
+}
+
+// HasPermission checks if one of the roles of the user grants the given
+// permission. This is needed to implement router.User
+func (u *User) HasPermission(permission string) bool {
+	for _, role := range u.Roles {
+		if roleHasPermission(role, permission) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Grant adds the given roles to the user. The roles the user already has
+// are ignored
+func (u *User) Grant(roles ...string) error {
+	for _, role := range roles {
+		if !IsValidRole(role) {
+			return apierror.NewInvalidParam("roles", "role [%s] does not exist", role)
+		}
+	}
+
+	for _, role := range roles {
+		granted := false
+		for _, r := range u.Roles {
+			granted = granted || r == role
+		}
+
+		if !granted {
+			u.Roles = append(u.Roles, role)
+		}
+	}
+
+	return nil
+}
+
+// SetPassword hashes and sets the password of the user
+func (u *User) SetPassword(password string) error {
+	if password == "" {
+		return apierror.NewInvalidParam("password", "password cannot be empty")
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return apierror.NewServerError("%s", err.Error())
+	}
+
+	u.Password = string(hash)
+	return nil
+}
+
+// IsPasswordValid checks if the given password matches the one of the user
+func (u *User) IsPasswordValid(password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(u.Password), []byte(password)) == nil
+}
+
+func (u *User) FullyDelete(db *mgo.Database) error {
+	if u == nil {
+		return errors.New("user not instanced")
+	}
+
+	if u.ID == "" {
+		return errors.New("user has not been saved")
+	}
+
+	return Query(db).RemoveId(u.ID)
+}
+
+func (u *User) Save(db *mgo.Database) error {
+	if u == nil {
+		return errors.New("user not instanced")
+	}
+
+	if u.ID == "" {
+		return u.Create(db)
+	}
+
+	return u.Update(db)
+}
+
+func (u *User) Create(db *mgo.Database) error {
+	if u == nil {
+		return apierror.NewServerError("user not instanced")
+	}
+
+	if err := u.validate(); err != nil {
+		return err
+	}
+
+	u.ID = bson.NewObjectId()
+	u.CreatedAt = time.Now()
+
+	if err := Query(db).Insert(u); err != nil {
+		u.ID = ""
+		if mgo.IsDup(err) {
+			return apierror.NewConflict("email [%s] already used", u.Email)
+		}
+		return apierror.NewServerError("%s", err.Error())
+	}
+
+	return nil
+}
+
+func (u *User) Update(db *mgo.Database) error {
+	if u == nil {
+		return apierror.NewServerError("user not instanced")
+	}
+
+	if u.ID == "" {
+		return apierror.NewServerError("cannot update a non-persisted user")
+	}
+
+	if err := u.validate(); err != nil {
+		return err
+	}
+
+	if err := Query(db).UpdateId(u.ID, u); err != nil {
+		if mgo.IsDup(err) {
+			return apierror.NewConflict("email [%s] already used", u.Email)
+		}
+
+		if err == mgo.ErrNotFound {
+			return apierror.NewNotFound("user [%s] not found", u.ID.Hex())
+		}
+
+		return apierror.NewServerError("%s", err.Error())
+	}
+
+	return nil
+}
+
+func (u *User) validate() error {
+	u.Email = normalizeEmail(u.Email)
+	if u.Email == "" {
+		return apierror.NewInvalidParam("email", "email cannot be empty")
+	}
+
+	if u.Password == "" {
+		return apierror.NewInvalidParam("password", "password cannot be empty")
+	}
+
+	for _, role := range u.Roles {
+		if !IsValidRole(role) {
+			return apierror.NewInvalidParam("roles", "role [%s] does not exist", role)
+		}
+	}
+
+	return nil
+}
+
+// NewTestUser creates and saves a new user. The password of the user will be
+// the same as its email
+func NewTestUser(t *testing.T, db *mgo.Database, u *User) *User {
+	if u == nil {
+		u = &User{}
+	}
+
+	if u.Name == "" {
+		u.Name = uniuri.New()
+	}
+
+	if u.Email == "" {
+		u.Email = strings.ToLower(uniuri.New()) + "@domain.tld"
+	}
+
+	if u.Password == "" {
+		if err := u.SetPassword(u.Email); err != nil {
+			t.Fatalf("failed to set the password: %s", err)
+		}
+	}
+
+	if err := u.Save(db); err != nil {
+		t.Fatalf("failed to save user: %s", err)
+	}
+	return u
+}
@@ -0,0 +1,33 @@
+package users_test
+
+import (
+	"testing"
+
+	"github.com/Nivl/api.melvin.la/api/components/users"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserGrant(t *testing.T) {
+	testCases := []struct {
+		description string
+		roles       []string
+		granted     []string
+		expected    []string
+		isValid     bool
+	}{
+		{"New role", []string{users.RoleAuthor}, []string{users.RoleEditor}, []string{users.RoleAuthor, users.RoleEditor}, true},
+		{"Already granted", []string{users.RoleAuthor}, []string{users.RoleAuthor, users.RoleAuthor}, []string{users.RoleAuthor}, true},
+		{"No roles", nil, []string{users.RoleAdmin}, []string{users.RoleAdmin}, true},
+		{"Unknown role", []string{users.RoleAuthor}, []string{users.RoleEditor, "nope"}, []string{users.RoleAuthor}, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			u := &users.User{Roles: tc.roles}
+			err := u.Grant(tc.granted...)
+
+			assert.Equal(t, tc.isValid, err == nil)
+			assert.Equal(t, tc.expected, u.Roles)
+		})
+	}
+}
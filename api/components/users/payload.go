@@ -0,0 +1,99 @@
+package users
+
+import "github.com/Nivl/api.melvin.la/api/app/helpers"
+
+// Exportable represents a User that can be safely returned by the API
+type Exportable struct {
+	ID        string   `json:"id"`
+	Name      string   `json:"name"`
+	Email     string   `json:"email"`
+	Roles     []string `json:"roles"`
+	CreatedAt string   `json:"created_at"`
+}
+
+// NewPayloadFromModel turns a User into an object that is safe to be
+// returned by the API
+func NewPayloadFromModel(u *User) *Exportable {
+	return &Exportable{
+		ID:        u.ID.Hex(),
+		Name:      u.Name,
+		Email:     u.Email,
+		Roles:     u.Roles,
+		CreatedAt: helpers.GetDateForJSON(u.CreatedAt),
+	}
+}
+
+// SessionExportable represents a Session that can be safely returned by the API
+type SessionExportable struct {
+	Token     string      `json:"token"`
+	ExpiresAt string      `json:"expires_at"`
+	User      *Exportable `json:"user"`
+}
+
+// NewSessionPayload turns a newly created Session into an object that is
+// safe to be returned by the API
+func NewSessionPayload(s *Session, u *User) *SessionExportable {
+	return &SessionExportable{
+		Token:     s.Token,
+		ExpiresAt: helpers.GetDateForJSON(s.ExpiresAt),
+		User:      NewPayloadFromModel(u),
+	}
+}
+
+// APIKeyExportable represents an APIKey that can be safely returned by the API
+type APIKeyExportable struct {
+	ID         string   `json:"id"`
+	Name       string   `json:"name"`
+	Prefix     string   `json:"prefix"`
+	Scopes     []string `json:"scopes"`
+	CreatedAt  string   `json:"created_at"`
+	ExpiresAt  string   `json:"expires_at,omitempty"`
+	RevokedAt  string   `json:"revoked_at,omitempty"`
+	LastUsedAt string   `json:"last_used_at,omitempty"`
+	LastUsedIP string   `json:"last_used_ip,omitempty"`
+
+	// Key is only set when the key has just been created
+	Key string `json:"key,omitempty"`
+}
+
+// NewAPIKeyPayload turns an APIKey into an object that is safe to be
+// returned by the API
+func NewAPIKeyPayload(k *APIKey) *APIKeyExportable {
+	pld := &APIKeyExportable{
+		ID:         k.ID.Hex(),
+		Name:       k.Name,
+		Prefix:     k.Prefix,
+		Scopes:     k.Scopes,
+		CreatedAt:  helpers.GetDateForJSON(k.CreatedAt),
+		LastUsedIP: k.LastUsedIP,
+		Key:        k.Key,
+	}
+
+	if pld.Scopes == nil {
+		pld.Scopes = []string{}
+	}
+
+	if !k.ExpiresAt.IsZero() {
+		pld.ExpiresAt = helpers.GetDateForJSON(k.ExpiresAt)
+	}
+
+	if !k.RevokedAt.IsZero() {
+		pld.RevokedAt = helpers.GetDateForJSON(k.RevokedAt)
+	}
+
+	if !k.LastUsedAt.IsZero() {
+		pld.LastUsedAt = helpers.GetDateForJSON(k.LastUsedAt)
+	}
+
+	return pld
+}
+
+// NewAPIKeyPayloads turns a []*APIKey into a list object that is safe to be
+// returned by the API
+func NewAPIKeyPayloads(list []*APIKey) []*APIKeyExportable {
+	pld := make([]*APIKeyExportable, len(list))
+	for i, k := range list {
+		pld[i] = NewAPIKeyPayload(k)
+	}
+	return pld
+}
@@ -0,0 +1,64 @@
+package users
+
+// List of the roles a user can have
+const (
+	// RoleAdmin grants all the permissions
+	RoleAdmin = "admin"
+
+	// RoleEditor can write and publish articles
+	RoleEditor = "editor"
+
+	// RoleAuthor can write articles, but cannot publish them
+	RoleAuthor = "author"
+)
+
+// List of the permissions that can be required by an endpoint
+const (
+	PermissionAdmin           = "admin"
+	PermissionArticlesWrite   = "articles:write"
+	PermissionArticlesPublish = "articles:publish"
+)
+
+// permissions contains all the existing permissions
+var permissions = map[string]bool{
+	PermissionAdmin:           true,
+	PermissionArticlesWrite:   true,
+	PermissionArticlesPublish: true,
+}
+
+// IsValidPermission checks if the given permission exists
+func IsValidPermission(permission string) bool {
+	return permissions[permission]
+}
+
+// rolesPermissions contains the permissions granted by each role.
+// RoleAdmin is not listed since it has all the permissions
+var rolesPermissions = map[string][]string{
+	RoleEditor: {PermissionArticlesWrite, PermissionArticlesPublish},
+	RoleAuthor: {PermissionArticlesWrite},
+}
+
+// IsValidRole checks if the given role exists
+func IsValidRole(role string) bool {
+	if role == RoleAdmin {
+		return true
+	}
+
+	_, ok := rolesPermissions[role]
+	return ok
+}
+
+// roleHasPermission checks if the given role grants the given permission
+func roleHasPermission(role string, permission string) bool {
+	if role == RoleAdmin {
+		return true
+	}
+
+	for _, p := range rolesPermissions[role] {
+		if p == permission {
+			return true
+		}
+	}
+
+	return false
+}
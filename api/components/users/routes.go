@@ -0,0 +1,69 @@
+package users
+
+import (
+	"github.com/Nivl/api.melvin.la/api/router"
+)
+
+const (
+	EndpointLogin = iota
+	EndpointLogout
+	EndpointListAPIKeys
+	EndpointAddAPIKey
+	EndpointUpdateAPIKey
+	EndpointRevokeAPIKey
+)
+
+var Endpoints = router.Endpoints{
+	EndpointLogin: {
+		Verb:    "POST",
+		Path:    "/sessions",
+		Handler: HandlerLogin,
+		Auth:    nil,
+		Params:  &HandlerLoginParams{},
+	},
+	EndpointLogout: {
+		Verb:         "DELETE",
+		Path:         "/sessions",
+		Handler:      HandlerLogout,
+		Auth:         Auth,
+		AuthRequired: true,
+	},
+	EndpointListAPIKeys: {
+		Verb:         "GET",
+		Path:         "/api-keys",
+		Handler:      HandlerListAPIKeys,
+		Auth:         Auth,
+		AuthRequired: true,
+	},
+	EndpointAddAPIKey: {
+		Verb:         "POST",
+		Path:         "/api-keys",
+		Handler:      HandlerAddAPIKey,
+		Auth:         Auth,
+		AuthRequired: true,
+		Params:       &HandlerAddAPIKeyParams{},
+
+		DisallowUnknownFields: true,
+	},
+	EndpointUpdateAPIKey: {
+		Verb:         "PATCH",
+		Path:         "/api-keys/{id}",
+		Handler:      HandlerUpdateAPIKey,
+		Auth:         Auth,
+		AuthRequired: true,
+		Params:       &HandlerUpdateAPIKeyParams{},
+	},
+	EndpointRevokeAPIKey: {
+		Verb:         "DELETE",
+		Path:         "/api-keys/{id}",
+		Handler:      HandlerRevokeAPIKey,
+		Auth:         Auth,
+		AuthRequired: true,
+		Params:       &HandlerRevokeAPIKeyParams{},
+	},
+}
+
+// SetRoutes is used to set all the routes of the users
+func SetRoutes(g *router.Group) {
+	g.Activate(Endpoints)
+}
@@ -0,0 +1,36 @@
+package users
+
+import (
+	"time"
+
+	"gopkg.in/mgo.v2"
+)
+
+// EnsureIndexes sets the indexes for the User, Session and APIKey documents
+func EnsureIndexes(db *mgo.Database) error {
+	indexes := map[string][]mgo.Index{
+		"user": {
+			mgo.Index{Key: []string{"email"}, Unique: true, Background: true},
+		},
+		"session": {
+			mgo.Index{Key: []string{"token_hash"}, Unique: true, Background: true},
+			// Mongo removes the expired sessions by itself
+			mgo.Index{Key: []string{"expires_at"}, ExpireAfter: time.Second, Background: true},
+		},
+		"api_key": {
+			mgo.Index{Key: []string{"key_hash"}, Unique: true, Background: true},
+			mgo.Index{Key: []string{"user_id", "-created_at"}, Background: true},
+		},
+	}
+
+	for name, list := range indexes {
+		doc := db.C(name)
+
+		for _, index := range list {
+			if err := doc.EnsureIndex(index); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
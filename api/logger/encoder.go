@@ -0,0 +1,99 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Entry represents a log entry
+type Entry struct {
+	Time    time.Time
+	Level   Level
+	Message string
+	Fields  Fields
+
+	// Stack contains the stack trace of the goroutine that created the entry.
+	// Only set for errors
+	Stack string
+}
+
+// Encoder turns a log entry into bytes
+type Encoder interface {
+	Encode(e *Entry) ([]byte, error)
+}
+
+// NewEncoder returns the encoder matching the given format (json or console)
+func NewEncoder(format string) (Encoder, error) {
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case "json", "":
+		return &JSONEncoder{}, nil
+	case "console":
+		return &ConsoleEncoder{}, nil
+	}
+	return nil, fmt.Errorf("unknown log format [%s]", format)
+}
+
+// JSONEncoder encodes the entries as a single line JSON object. The fields
+// are added at the root of the object
+type JSONEncoder struct{}
+
+// Encode implements the Encoder interface
+func (enc *JSONEncoder) Encode(e *Entry) ([]byte, error) {
+	obj := make(map[string]interface{}, len(e.Fields)+4)
+	for k, v := range e.Fields {
+		// errors don't have any exported fields
+		if err, isErr := v.(error); isErr {
+			v = err.Error()
+		}
+		obj[k] = v
+	}
+
+	obj["time"] = e.Time.UTC().Format(time.RFC3339Nano)
+	obj["level"] = e.Level.String()
+	obj["message"] = e.Message
+	if e.Stack != "" {
+		obj["stack"] = e.Stack
+	}
+
+	dump, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	return append(dump, '\n'), nil
+}
+
+// ConsoleEncoder encodes the entries in a human-readable format
+//
+//	2017-03-04T12:30:00Z INFO  request handled method=GET status=200
+type ConsoleEncoder struct{}
+
+// Encode implements the Encoder interface
+func (enc *ConsoleEncoder) Encode(e *Entry) ([]byte, error) {
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "%s %-5s %s", e.Time.UTC().Format(time.RFC3339), strings.ToUpper(e.Level.String()), e.Message)
+
+	keys := make([]string, 0, len(e.Fields))
+	for k := range e.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		fmt.Fprintf(&buf, " %s=%v", k, e.Fields[k])
+	}
+	buf.WriteByte('\n')
+
+	if e.Stack != "" {
+		buf.WriteString(e.Stack)
+		if !strings.HasSuffix(e.Stack, "\n") {
+			buf.WriteByte('\n')
+		}
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,49 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Level represents the severity of a log entry
+type Level int
+
+// List of the available levels, from the least to the most severe
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+var levelNames = map[Level]string{
+	LevelDebug: "debug",
+	LevelInfo:  "info",
+	LevelWarn:  "warn",
+	LevelError: "error",
+}
+
+// String returns the name of the level
+func (l Level) String() string {
+	if name, found := levelNames[l]; found {
+		return name
+	}
+	return fmt.Sprintf("level(%d)", int(l))
+}
+
+// ParseLevel returns the level matching the given name
+func ParseLevel(name string) (Level, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for level, levelName := range levelNames {
+		if levelName == name {
+			return level, nil
+		}
+	}
+
+	// "warning" is commonly used
+	if name == "warning" {
+		return LevelWarn, nil
+	}
+
+	return LevelInfo, fmt.Errorf("unknown log level [%s]", name)
+}
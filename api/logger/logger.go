@@ -2,28 +2,308 @@ package logger
 
 import (
 	"fmt"
-	"log"
+	"os"
 	"runtime/debug"
+	"strings"
+	"time"
 
-	"github.com/Nivl/api.melvin.la/api/app"
+	"github.com/bsphere/le_go"
 )
 
-func logg(msg string) {
-	context := app.GetContext()
+// List of the available sinks
+const (
+	SinkStdout     = "stdout"
+	SinkFile       = "file"
+	SinkSyslog     = "syslog"
+	SinkLogEntries = "logentries"
+)
+
+// Fields represents the key/value data attached to a log entry
+type Fields map[string]interface{}
+
+// Logger represents a leveled and structured logger
+type Logger struct {
+	level   Level
+	encoder Encoder
+	fields  Fields
 
-	if context != nil && context.LogEntries != nil {
-		context.LogEntries.Println(msg)
+	// sink is shared by a logger and all its children
+	sink Sink
+}
+
+// New returns a logger writing the entries of level or above to sink
+func New(sink Sink, encoder Encoder, level Level) *Logger {
+	return &Logger{
+		level:   level,
+		encoder: encoder,
+		fields:  Fields{},
+		sink:    sink,
 	}
+}
 
-	log.Println(msg)
+// std is the logger used by the package-level functions, and by the nil
+// loggers. The apps have their own logger, created using NewFromConfig
+var std = New(NewWriterSink(os.Stdout), &JSONEncoder{}, LevelInfo)
+
+// Default returns the logger used by the package-level functions
+func Default() *Logger {
+	return std
 }
 
-func Errorf(msg string, args ...interface{}) {
-	fullMessage := fmt.Sprintf("%s | \"level\": \"ERROR\", %s", debug.Stack(), fmt.Sprintf(msg, args...))
-	logg(fullMessage)
+// Config contains the options of a logger created by NewFromConfig
+type Config struct {
+	// Level contains the minimum level of the entries to write, and Format
+	// the format of the entries (see ParseLevel and NewEncoder)
+	Level  string
+	Format string
+
+	// Debug logs everything, whatever the level
+	Debug bool
+
+	// Sinks contains where the entries should be written (stdout, file,
+	// syslog, logentries)
+	Sinks []string
+
+	// BufferSize contains the number of entries that can be waiting to be
+	// written
+	BufferSize int
+
+	// File contains the path of the file used by the file sink, which is
+	// rotated once it reaches FileMaxSize bytes
+	File           string
+	FileMaxSize    int64
+	FileMaxBackups int
+
+	// LogEntries contains the connection used by the logentries sink
+	LogEntries *le_go.Logger
+}
+
+// NewFromConfig returns a logger using the given config. The entries are
+// written asynchronously, and need to be flushed using Close()
+func NewFromConfig(cfg Config) (*Logger, error) {
+	level, err := ParseLevel(cfg.Level)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.Debug {
+		level = LevelDebug
+	}
+
+	encoder, err := NewEncoder(cfg.Format)
+	if err != nil {
+		return nil, err
+	}
+
+	sinks, err := newSinks(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return New(NewAsyncSink(sinks, cfg.BufferSize), encoder, level), nil
+}
+
+// newSinks returns the sinks listed in the config
+func newSinks(cfg Config) (MultiSink, error) {
+	sinks := MultiSink{}
+	used := map[string]bool{}
+	for _, name := range cfg.Sinks {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if used[name] {
+			continue
+		}
+		used[name] = true
+
+		var sink Sink
+		var err error
+
+		switch name {
+		case SinkStdout:
+			sink = NewWriterSink(os.Stdout)
+		case SinkFile:
+			sink, err = NewFileSink(cfg.File, cfg.FileMaxSize, cfg.FileMaxBackups)
+		case SinkSyslog:
+			sink, err = NewSyslogSink("api.melvin.la")
+		case SinkLogEntries:
+			if cfg.LogEntries == nil {
+				err = fmt.Errorf("no LogEntries connection")
+				break
+			}
+			sink = NewLogEntriesSink(cfg.LogEntries)
+		default:
+			err = fmt.Errorf("unknown sink [%s]", name)
+		}
+
+		if err != nil {
+			sinks.Close()
+			return nil, fmt.Errorf("could not create the %s log sink: %s", name, err.Error())
+		}
+		sinks = append(sinks, sink)
+	}
+
+	return sinks, nil
+}
+
+// Close flushes and closes the sink of the logger. The sink is shared with
+// the children of the logger, which cannot be used anymore
+func (l *Logger) Close() error {
+	if l == nil {
+		return nil
+	}
+	return l.sink.Close()
+}
+
+// CloseTimeout works like Close, but waits at most timeout for the pending
+// entries to be written, when the sink supports it
+func (l *Logger) CloseTimeout(timeout time.Duration) error {
+	if l == nil {
+		return nil
+	}
+
+	if s, ok := l.sink.(interface {
+		CloseTimeout(time.Duration) error
+	}); ok {
+		return s.CloseTimeout(timeout)
+	}
+	return l.sink.Close()
+}
+
+// With returns a child logger adding the given fields to all its entries
+func (l *Logger) With(fields Fields) *Logger {
+	if l == nil {
+		l = std
+	}
+
+	child := *l
+	child.fields = make(Fields, len(l.fields)+len(fields))
+	for k, v := range l.fields {
+		child.fields[k] = v
+	}
+	for k, v := range fields {
+		child.fields[k] = v
+	}
+	return &child
+}
+
+// Enabled returns true if the entries of the given level are logged
+func (l *Logger) Enabled(level Level) bool {
+	if l == nil {
+		l = std
+	}
+	return level >= l.level
+}
+
+func (l *Logger) log(level Level, msg string) {
+	if l == nil {
+		l = std
+	}
+
+	if !l.Enabled(level) {
+		return
+	}
+
+	e := &Entry{
+		Time:    time.Now(),
+		Level:   level,
+		Message: msg,
+		Fields:  l.fields,
+	}
+
+	if level >= LevelError {
+		e.Stack = string(debug.Stack())
+	}
+
+	dump, err := l.encoder.Encode(e)
+	if err != nil {
+		dump = []byte(fmt.Sprintf("could not encode the log entry [%s]: %s\n", msg, err.Error()))
+	}
+
+	// There's not much we can do if the sink fails
+	l.sink.Write(level, dump)
+}
+
+// Debug logs a message useful when debugging
+func (l *Logger) Debug(msg string) {
+	l.log(LevelDebug, msg)
+}
+
+// Debugf logs a formatted message useful when debugging
+func (l *Logger) Debugf(msg string, args ...interface{}) {
+	l.log(LevelDebug, fmt.Sprintf(msg, args...))
+}
+
+// Info logs an informative message
+func (l *Logger) Info(msg string) {
+	l.log(LevelInfo, msg)
+}
+
+// Infof logs a formatted informative message
+func (l *Logger) Infof(msg string, args ...interface{}) {
+	l.log(LevelInfo, fmt.Sprintf(msg, args...))
+}
+
+// Warn logs a message about something that may need attention
+func (l *Logger) Warn(msg string) {
+	l.log(LevelWarn, msg)
 }
 
+// Warnf logs a formatted message about something that may need attention
+func (l *Logger) Warnf(msg string, args ...interface{}) {
+	l.log(LevelWarn, fmt.Sprintf(msg, args...))
+}
+
+// Error logs an error, with the current stack trace
+func (l *Logger) Error(msg string) {
+	l.log(LevelError, msg)
+}
+
+// Errorf logs a formatted error, with the current stack trace
+func (l *Logger) Errorf(msg string, args ...interface{}) {
+	l.log(LevelError, fmt.Sprintf(msg, args...))
+}
+
+// With returns a child of the default logger adding the given fields to all
+// its entries
+func With(fields Fields) *Logger {
+	return std.With(fields)
+}
+
+// Debug logs a message useful when debugging
+func Debug(msg string) {
+	std.log(LevelDebug, msg)
+}
+
+// Debugf logs a formatted message useful when debugging
+func Debugf(msg string, args ...interface{}) {
+	std.log(LevelDebug, fmt.Sprintf(msg, args...))
+}
+
+// Info logs an informative message
+func Info(msg string) {
+	std.log(LevelInfo, msg)
+}
+
+// Infof logs a formatted informative message
+func Infof(msg string, args ...interface{}) {
+	std.log(LevelInfo, fmt.Sprintf(msg, args...))
+}
+
+// Warn logs a message about something that may need attention
+func Warn(msg string) {
+	std.log(LevelWarn, msg)
+}
+
+// Warnf logs a formatted message about something that may need attention
+func Warnf(msg string, args ...interface{}) {
+	std.log(LevelWarn, fmt.Sprintf(msg, args...))
+}
+
+// Error logs an error, with the current stack trace
 func Error(msg string) {
-	fullMessage := fmt.Sprintf("%s | \"level\": \"ERROR\", %s", debug.Stack(), msg)
-	logg(fullMessage)
+	std.log(LevelError, msg)
+}
+
+// Errorf logs a formatted error, with the current stack trace
+func Errorf(msg string, args ...interface{}) {
+	std.log(LevelError, fmt.Sprintf(msg, args...))
 }
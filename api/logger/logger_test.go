@@ -0,0 +1,98 @@
+package logger_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Nivl/api.melvin.la/api/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewFromConfigInvalid(t *testing.T) {
+	testCases := []struct {
+		description string
+		cfg         logger.Config
+	}{
+		{"Unknown level", logger.Config{Level: "verbose", Format: "json"}},
+		{"Unknown format", logger.Config{Level: "info", Format: "xml"}},
+		{"Unknown sink", logger.Config{Level: "info", Format: "json", Sinks: []string{"kafka"}}},
+		{"LogEntries without connection", logger.Config{Level: "info", Format: "json", Sinks: []string{"logentries"}}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			l, err := logger.NewFromConfig(tc.cfg)
+			assert.Error(t, err)
+			assert.Nil(t, l)
+		})
+	}
+}
+
+func TestNewFromConfigIsolated(t *testing.T) {
+	dir := t.TempDir()
+
+	newLogger := func(name, level, format string) *logger.Logger {
+		l, err := logger.NewFromConfig(logger.Config{
+			Level:  level,
+			Format: format,
+			Sinks:  []string{"file"},
+			File:   filepath.Join(dir, name),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return l
+	}
+
+	jsonLogger := newLogger("json.log", "warn", "json")
+	consoleLogger := newLogger("console.log", "debug", "console")
+
+	jsonLogger.Info("ignored")
+	jsonLogger.Warn("json entry")
+	consoleLogger.Debug("console entry")
+
+	// Closing a logger must not close the sinks of the other one
+	assert.NoError(t, jsonLogger.Close())
+	consoleLogger.Debug("after close")
+	assert.NoError(t, consoleLogger.Close())
+
+	jsonLogs, err := os.ReadFile(filepath.Join(dir, "json.log"))
+	if assert.NoError(t, err) {
+		lines := strings.Split(strings.TrimSpace(string(jsonLogs)), "\n")
+		if assert.Len(t, lines, 1) {
+			assert.True(t, strings.HasPrefix(lines[0], "{"), "expected a JSON entry, got %s", lines[0])
+			assert.Contains(t, lines[0], "json entry")
+		}
+	}
+
+	consoleLogs, err := os.ReadFile(filepath.Join(dir, "console.log"))
+	if assert.NoError(t, err) {
+		lines := strings.Split(strings.TrimSpace(string(consoleLogs)), "\n")
+		if assert.Len(t, lines, 2) {
+			assert.False(t, strings.HasPrefix(lines[0], "{"), "expected a console entry, got %s", lines[0])
+			assert.Contains(t, lines[0], "console entry")
+			assert.Contains(t, lines[1], "after close")
+		}
+	}
+}
+
+func TestLoggerCloseTimeout(t *testing.T) {
+	sink := newBlockingSink()
+	l := logger.New(logger.NewAsyncSink(sink, 10), &logger.JSONEncoder{}, logger.LevelInfo)
+	l.Info("pending")
+	<-sink.started
+
+	// The timeout is forwarded to the async sink
+	start := time.Now()
+	assert.Error(t, l.CloseTimeout(20*time.Millisecond))
+	assert.True(t, time.Since(start) < time.Second, "CloseTimeout() should not wait for the sink")
+	close(sink.release)
+
+	// Other sinks are simply closed
+	syncSink := newBlockingSink()
+	assert.NoError(t, logger.New(syncSink, &logger.JSONEncoder{}, logger.LevelInfo).CloseTimeout(time.Millisecond))
+	assert.True(t, syncSink.closed)
+}
@@ -0,0 +1,67 @@
+package logger
+
+import (
+	"io"
+	"sync"
+)
+
+// Sink represents a destination for the log entries
+type Sink interface {
+	// Write writes an encoded entry of the given level
+	Write(level Level, data []byte) error
+
+	// Close flushes the pending entries and releases the resources used by
+	// the sink
+	Close() error
+}
+
+// WriterSink is a sink writing the entries to an io.Writer, like os.Stdout
+type WriterSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewWriterSink returns a sink writing the entries to w
+func NewWriterSink(w io.Writer) *WriterSink {
+	return &WriterSink{w: w}
+}
+
+// Write implements the Sink interface
+func (s *WriterSink) Write(level Level, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err := s.w.Write(data)
+	return err
+}
+
+// Close implements the Sink interface. The writer is not closed
+func (s *WriterSink) Close() error {
+	return nil
+}
+
+// MultiSink is a sink duplicating the entries to several sinks
+type MultiSink []Sink
+
+// Write implements the Sink interface. All the sinks are written even if
+// one of them fails, and the first error is returned
+func (sinks MultiSink) Write(level Level, data []byte) error {
+	var firstErr error
+	for _, s := range sinks {
+		if err := s.Write(level, data); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// Close implements the Sink interface
+func (sinks MultiSink) Close() error {
+	var firstErr error
+	for _, s := range sinks {
+		if err := s.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
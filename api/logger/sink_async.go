@@ -0,0 +1,127 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultCloseTimeout is the maximum amount of time AsyncSink.Close waits for
+// the pending entries to be written
+const DefaultCloseTimeout = 5 * time.Second
+
+type asyncEntry struct {
+	level Level
+	data  []byte
+}
+
+// AsyncSink writes the entries to a sink from a background goroutine, so a
+// slow sink doesn't slow down the callers. The entries are dropped when the
+// buffer is full
+type AsyncSink struct {
+	sink    Sink
+	entries chan *asyncEntry
+	done    chan struct{}
+	dropped uint64
+	failed  uint64
+
+	// mu prevents entries from being sent while the channel is being closed
+	mu     sync.RWMutex
+	closed bool
+
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// NewAsyncSink returns a sink buffering up to size entries before writing
+// them to sink
+func NewAsyncSink(sink Sink, size int) *AsyncSink {
+	if size < 1 {
+		size = 1
+	}
+
+	s := &AsyncSink{
+		sink:    sink,
+		entries: make(chan *asyncEntry, size),
+		done:    make(chan struct{}),
+	}
+
+	go s.run()
+	return s
+}
+
+func (s *AsyncSink) run() {
+	defer close(s.done)
+
+	for e := range s.entries {
+		if err := s.sink.Write(e.level, e.data); err != nil {
+			atomic.AddUint64(&s.failed, 1)
+		}
+	}
+}
+
+// Write implements the Sink interface. The entry is dropped if the buffer
+// is full
+func (s *AsyncSink) Write(level Level, data []byte) error {
+	// The caller may reuse data once we return
+	e := &asyncEntry{level: level, data: append([]byte(nil), data...)}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.closed {
+		atomic.AddUint64(&s.dropped, 1)
+		return fmt.Errorf("sink closed")
+	}
+
+	select {
+	case s.entries <- e:
+		return nil
+	default:
+		atomic.AddUint64(&s.dropped, 1)
+		return fmt.Errorf("log buffer full, entry dropped")
+	}
+}
+
+// Dropped returns the number of entries that have been dropped because the
+// buffer was full
+func (s *AsyncSink) Dropped() uint64 {
+	return atomic.LoadUint64(&s.dropped)
+}
+
+// Failed returns the number of entries the underlying sink failed to write
+func (s *AsyncSink) Failed() uint64 {
+	return atomic.LoadUint64(&s.failed)
+}
+
+// Close implements the Sink interface. The pending entries are written
+// before closing the underlying sink, for up to DefaultCloseTimeout
+func (s *AsyncSink) Close() error {
+	return s.CloseTimeout(DefaultCloseTimeout)
+}
+
+// CloseTimeout works like Close with a custom timeout
+func (s *AsyncSink) CloseTimeout(timeout time.Duration) error {
+	s.closeOnce.Do(func() {
+		s.mu.Lock()
+		s.closed = true
+		close(s.entries)
+		s.mu.Unlock()
+
+		if dropped, failed := s.Dropped(), s.Failed(); dropped > 0 || failed > 0 {
+			fmt.Fprintf(os.Stderr, "logger: %d entries dropped, %d entries failed to be written\n", dropped, failed)
+		}
+
+		select {
+		case <-s.done:
+			s.closeErr = s.sink.Close()
+		case <-time.After(timeout):
+			// The underlying sink is still being used by the goroutine, so we
+			// can't close it
+			s.closeErr = fmt.Errorf("timed out after %s with %d log entries pending", timeout, len(s.entries))
+		}
+	})
+	return s.closeErr
+}
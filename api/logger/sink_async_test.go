@@ -0,0 +1,175 @@
+package logger_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Nivl/api.melvin.la/api/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+// blockingSink is a sink whose writes block until release is closed
+type blockingSink struct {
+	started chan struct{}
+	release chan struct{}
+
+	mu      sync.Mutex
+	entries []string
+	closed  bool
+}
+
+func newBlockingSink() *blockingSink {
+	return &blockingSink{
+		started: make(chan struct{}, 100),
+		release: make(chan struct{}),
+	}
+}
+
+func (s *blockingSink) Write(level logger.Level, data []byte) error {
+	s.started <- struct{}{}
+	<-s.release
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = append(s.entries, string(data))
+	return nil
+}
+
+func (s *blockingSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	return nil
+}
+
+// failingSink is a sink failing all its writes
+type failingSink struct{}
+
+func (s *failingSink) Write(level logger.Level, data []byte) error {
+	return fmt.Errorf("write failed")
+}
+
+func (s *failingSink) Close() error {
+	return nil
+}
+
+func TestAsyncSinkBufferFull(t *testing.T) {
+	sink := newBlockingSink()
+	async := logger.NewAsyncSink(sink, 1)
+
+	// The first entry is being written, the second one fills the buffer
+	assert.NoError(t, async.Write(logger.LevelInfo, []byte("first")))
+	<-sink.started
+	assert.NoError(t, async.Write(logger.LevelInfo, []byte("second")))
+
+	assert.Error(t, async.Write(logger.LevelInfo, []byte("third")))
+	assert.Error(t, async.Write(logger.LevelInfo, []byte("fourth")))
+	assert.Equal(t, uint64(2), async.Dropped())
+
+	close(sink.release)
+	assert.NoError(t, async.Close())
+	assert.Equal(t, []string{"first", "second"}, sink.entries)
+	assert.True(t, sink.closed)
+	assert.Equal(t, uint64(0), async.Failed())
+}
+
+func TestAsyncSinkWriteCopiesData(t *testing.T) {
+	sink := newBlockingSink()
+	close(sink.release)
+	async := logger.NewAsyncSink(sink, 10)
+
+	data := []byte("entry")
+	assert.NoError(t, async.Write(logger.LevelInfo, data))
+	copy(data, "reuse")
+
+	assert.NoError(t, async.Close())
+	assert.Equal(t, []string{"entry"}, sink.entries)
+}
+
+func TestAsyncSinkCloseDrains(t *testing.T) {
+	sink := newBlockingSink()
+	async := logger.NewAsyncSink(sink, 10)
+
+	for i := 0; i < 5; i++ {
+		assert.NoError(t, async.Write(logger.LevelInfo, []byte(fmt.Sprintf("%d", i))))
+	}
+
+	// The sink is released after Close() started waiting
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		close(sink.release)
+	}()
+
+	assert.NoError(t, async.CloseTimeout(time.Second))
+	assert.Equal(t, []string{"0", "1", "2", "3", "4"}, sink.entries)
+	assert.True(t, sink.closed)
+}
+
+func TestAsyncSinkCloseTimeout(t *testing.T) {
+	sink := newBlockingSink()
+	async := logger.NewAsyncSink(sink, 10)
+
+	assert.NoError(t, async.Write(logger.LevelInfo, []byte("first")))
+	assert.NoError(t, async.Write(logger.LevelInfo, []byte("second")))
+	<-sink.started
+
+	start := time.Now()
+	assert.Error(t, async.CloseTimeout(20*time.Millisecond))
+	assert.True(t, time.Since(start) < time.Second, "Close() should not wait for the sink")
+
+	// The sink is still in use, so it must not be closed
+	sink.mu.Lock()
+	assert.False(t, sink.closed)
+	sink.mu.Unlock()
+
+	// The error is kept for the next calls
+	assert.Error(t, async.Close())
+
+	close(sink.release)
+}
+
+func TestAsyncSinkWriteAfterClose(t *testing.T) {
+	sink := newBlockingSink()
+	close(sink.release)
+	async := logger.NewAsyncSink(sink, 10)
+	assert.NoError(t, async.Close())
+
+	assert.Error(t, async.Write(logger.LevelInfo, []byte("late")))
+	assert.Equal(t, uint64(1), async.Dropped())
+	assert.Empty(t, sink.entries)
+}
+
+func TestAsyncSinkFailed(t *testing.T) {
+	async := logger.NewAsyncSink(&failingSink{}, 10)
+
+	assert.NoError(t, async.Write(logger.LevelInfo, []byte("first")))
+	assert.NoError(t, async.Write(logger.LevelInfo, []byte("second")))
+	assert.NoError(t, async.Close())
+
+	assert.Equal(t, uint64(2), async.Failed())
+	assert.Equal(t, uint64(0), async.Dropped())
+}
+
+// TestAsyncSinkConcurrentClose is meant to be ran with -race
+func TestAsyncSinkConcurrentClose(t *testing.T) {
+	async := logger.NewAsyncSink(&failingSink{}, 10)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				async.Write(logger.LevelInfo, []byte("entry"))
+			}
+		}()
+	}
+
+	async.Close()
+	wg.Wait()
+
+	// Every entry is either written or dropped
+	assert.Equal(t, uint64(1000), async.Failed()+async.Dropped())
+}
@@ -0,0 +1,103 @@
+package logger_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Nivl/api.melvin.la/api/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+// readFile returns the content of the file at path, or "<missing>"
+func readFile(t *testing.T, path string) string {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return "<missing>"
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestFileSinkRotation(t *testing.T) {
+	testCases := []struct {
+		description string
+		maxSize     int64
+		maxBackups  int
+		expected    map[string]string
+	}{
+		{
+			"No rotation",
+			0, 2,
+			map[string]string{"api.log": "1...\n2...\n3...\n4...\n", "api.log.1": "<missing>"},
+		},
+		{
+			"Backups are shifted",
+			8, 2,
+			map[string]string{"api.log": "4...\n", "api.log.1": "3...\n", "api.log.2": "2...\n", "api.log.3": "<missing>"},
+		},
+		{
+			"Several entries per file",
+			10, 5,
+			map[string]string{"api.log": "3...\n4...\n", "api.log.1": "1...\n2...\n", "api.log.2": "<missing>"},
+		},
+		{
+			"No backups",
+			8, 0,
+			map[string]string{"api.log": "4...\n", "api.log.1": "<missing>"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "api.log")
+
+			sink, err := logger.NewFileSink(path, tc.maxSize, tc.maxBackups)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, entry := range []string{"1...\n", "2...\n", "3...\n", "4...\n"} {
+				assert.NoError(t, sink.Write(logger.LevelInfo, []byte(entry)))
+			}
+			assert.NoError(t, sink.Close())
+
+			for name, content := range tc.expected {
+				assert.Equal(t, content, readFile(t, filepath.Join(dir, name)), name)
+			}
+		})
+	}
+}
+
+func TestFileSinkExistingFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "api.log")
+	if err := os.WriteFile(path, []byte("old.\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The size of the existing file counts toward the rotation
+	sink, err := logger.NewFileSink(path, 8, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NoError(t, sink.Write(logger.LevelInfo, []byte("new.\n")))
+	assert.NoError(t, sink.Close())
+
+	assert.Equal(t, "new.\n", readFile(t, path))
+	assert.Equal(t, "old.\n", readFile(t, path+".1"))
+}
+
+func TestFileSinkClosed(t *testing.T) {
+	sink, err := logger.NewFileSink(filepath.Join(t.TempDir(), "api.log"), 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.NoError(t, sink.Close())
+	assert.NoError(t, sink.Close(), "Close() should be idempotent")
+	assert.Error(t, sink.Write(logger.LevelInfo, []byte("late\n")))
+}
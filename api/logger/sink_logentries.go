@@ -0,0 +1,25 @@
+package logger
+
+import "github.com/bsphere/le_go"
+
+// LogEntriesSink is a sink sending the entries to LogEntries
+type LogEntriesSink struct {
+	le *le_go.Logger
+}
+
+// NewLogEntriesSink returns a sink using the given LogEntries connection.
+// The connection is not closed by the sink
+func NewLogEntriesSink(le *le_go.Logger) *LogEntriesSink {
+	return &LogEntriesSink{le: le}
+}
+
+// Write implements the Sink interface
+func (s *LogEntriesSink) Write(level Level, data []byte) error {
+	_, err := s.le.Write(data)
+	return err
+}
+
+// Close implements the Sink interface
+func (s *LogEntriesSink) Close() error {
+	return nil
+}
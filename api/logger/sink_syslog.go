@@ -0,0 +1,43 @@
+package logger
+
+import (
+	"log/syslog"
+	"strings"
+)
+
+// SyslogSink is a sink sending the entries to the local syslog daemon
+type SyslogSink struct {
+	w *syslog.Writer
+}
+
+// NewSyslogSink connects to the local syslog daemon using its unix socket.
+// The entries are sent with the given tag
+func NewSyslogSink(tag string) (*SyslogSink, error) {
+	w, err := syslog.Dial("", "", syslog.LOG_INFO|syslog.LOG_DAEMON, tag)
+	if err != nil {
+		return nil, err
+	}
+	return &SyslogSink{w: w}, nil
+}
+
+// Write implements the Sink interface. The level of the entry is used as
+// syslog severity
+func (s *SyslogSink) Write(level Level, data []byte) error {
+	msg := strings.TrimSuffix(string(data), "\n")
+
+	switch level {
+	case LevelDebug:
+		return s.w.Debug(msg)
+	case LevelInfo:
+		return s.w.Info(msg)
+	case LevelWarn:
+		return s.w.Warning(msg)
+	default:
+		return s.w.Err(msg)
+	}
+}
+
+// Close implements the Sink interface
+func (s *SyslogSink) Close() error {
+	return s.w.Close()
+}
@@ -1,18 +1,199 @@
 package main
 
 import (
-	"net/http"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
 
 	"github.com/Nivl/api.melvin.la/api/app"
-	"github.com/Nivl/api.melvin.la/api/components/api"
 )
 
+// Build information, set at compile time using -ldflags
+var (
+	Version   string
+	Build     string
+	BuildTime string
+)
+
+// command represents a subcommand of the binary
+type command struct {
+	Name        string
+	Args        string
+	Description string
+
+	// Run runs the command with the args following its name
+	Run func(connect connectFunc, out *output, args []string) error
+}
+
+// connectFunc returns the app used by the commands. The app is only created
+// when a command needs it, so the help and the invalid args are handled
+// without connecting to the database
+type connectFunc func() (*app.Context, error)
+
+// commands contains all the subcommands of the binary, in the order they
+// are listed by the help
+var commands = []*command{
+	{"serve", "", "start the HTTP server (default)", serve},
+	{"migrate", "status|up|down|dry-run", "manage the migrations", migrate},
+	{"user", "create|reset-password|grant", "manage the users", user},
+	{"articles", "export|import", "backup and restore the articles", articlesCmd},
+	{"reindex", "", "create the missing indexes", reindex},
+	{"purge-trash", "[-retention DURATION]", "delete the articles trashed for too long", purgeTrash},
+}
+
 func main() {
-	app.InitContex()
-	defer app.GetContext().Destroy()
+	// os.Exit() does not run the defers, so everything is done in run()
+	os.Exit(run(os.Args[1:]))
+}
+
+// run runs the command matching the given args, and returns the exit code
+// of the app. The server is started when no command is given
+func run(args []string) int {
+	fs := newFlagSet("api")
+	format := fs.String("output", outputHuman, "format of the output: human or json")
+	if err := parseFlags(fs, args); err != nil {
+		if err == errHelp {
+			printUsage(os.Stdout, fs)
+			return exitOK
+		}
+		fmt.Fprintf(os.Stderr, "error: %s\n\n", err.Error())
+		printUsage(os.Stderr, fs)
+		return exitUsage
+	}
+
+	out, err := newOutput(*format, os.Stdout, os.Stderr)
+	if err != nil {
+		out.Error(err)
+		return exitCode(err)
+	}
+
+	args = fs.Args()
+	name := "serve"
+	if len(args) > 0 {
+		name, args = args[0], args[1:]
+	}
+
+	if name == "help" {
+		printUsage(os.Stdout, fs)
+		return exitOK
+	}
+
+	cmd := findCommand(name)
+	if cmd == nil {
+		err := newUsageError("unknown command [%s], run \"api help\" for the list of commands", name)
+		out.Error(err)
+		return exitCode(err)
+	}
+
+	var appCtx *app.Context
+	defer func() {
+		if appCtx != nil {
+			appCtx.Destroy()
+		}
+	}()
+
+	connect := func() (*app.Context, error) {
+		if appCtx != nil {
+			return appCtx, nil
+		}
+
+		params, err := app.ParseArgs()
+		if err != nil {
+			return nil, err
+		}
+
+		appCtx, err = app.New(params)
+		if err != nil {
+			return nil, err
+		}
+
+		appCtx.Build = app.BuildInfo{
+			Version:   Version,
+			Commit:    Build,
+			BuildTime: BuildTime,
+		}
+		return appCtx, nil
+	}
+
+	if err := cmd.Run(connect, out, args); err != nil {
+		if err == errHelp {
+			return exitOK
+		}
+		out.Error(err)
+		return exitCode(err)
+	}
+	return exitOK
+}
+
+// findCommand returns the command having the given name, or nil
+func findCommand(name string) *command {
+	for _, cmd := range commands {
+		if cmd.Name == name {
+			return cmd
+		}
+	}
+	return nil
+}
+
+// printUsage prints the help of the binary
+func printUsage(w io.Writer, fs *flag.FlagSet) {
+	fmt.Fprintln(w, "usage: api [-output human|json] <command> [args]")
+	fmt.Fprintln(w, "\ncommands:")
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	for _, cmd := range commands {
+		fmt.Fprintf(tw, "  %s %s\t%s\n", cmd.Name, cmd.Args, cmd.Description)
+	}
+	tw.Flush()
+	fmt.Fprintln(w, "\nflags:")
+	fs.SetOutput(w)
+	fs.PrintDefaults()
+	fmt.Fprintln(w, "\nThe configuration is read from the environment (API_MONGO_URI, etc.)")
+}
+
+// errHelp is returned when the help of a command has been printed
+var errHelp = errors.New("help requested")
+
+// newFlagSet returns a FlagSet that does not print anything nor exit by
+// itself, so the errors can be reported using the output of the app
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	return fs
+}
+
+// parseFlags parses the given args. errHelp is returned if -h has been
+// provided, and a usage error if the args are invalid
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	err := fs.Parse(args)
+	if err == flag.ErrHelp {
+		return errHelp
+	}
+	if err != nil {
+		return newUsageError("%s", err.Error())
+	}
+	return nil
+}
+
+// isFlagSet checks if the flag having the given name has been provided
+func isFlagSet(fs *flag.FlagSet, name string) bool {
+	found := false
+	fs.Visit(func(f *flag.Flag) {
+		found = found || f.Name == name
+	})
+	return found
+}
 
-	api.EnsureIndexes()
-	r := api.GetRouter()
-	port := ":" + app.GetContext().Params.Port
-	http.ListenAndServe(port, r)
+// parseCommandFlags parses the given args like parseFlags, and prints the
+// usage of the command if -h has been provided
+func parseCommandFlags(fs *flag.FlagSet, usage string, args []string) error {
+	err := parseFlags(fs, args)
+	if err == errHelp {
+		fmt.Fprintf(os.Stdout, "usage: api %s\n", usage)
+		fs.SetOutput(os.Stdout)
+		fs.PrintDefaults()
+	}
+	return err
 }
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRunWithoutDatabase checks the commands that should not connect to
+// the database. A connection attempt would fail with exitFailure, since
+// the tests don't provide a reachable database
+func TestRunWithoutDatabase(t *testing.T) {
+	t.Setenv("API_MONGO_URI", "mongodb://127.0.0.1:1/unreachable")
+
+	testCases := []struct {
+		args string
+		code int
+	}{
+		{"help", exitOK},
+		{"-h", exitOK},
+		{"serve -h", exitOK},
+		{"migrate -h", exitOK},
+		{"user -h", exitOK},
+		{"user create -h", exitOK},
+		{"user grant -h", exitOK},
+		{"articles -h", exitOK},
+		{"articles export -h", exitOK},
+		{"reindex -h", exitOK},
+		{"purge-trash -h", exitOK},
+		{"-output xml migrate status", exitUsage},
+		{"-unknown", exitUsage},
+		{"unknown", exitUsage},
+		{"serve now", exitUsage},
+		{"migrate", exitUsage},
+		{"migrate dry-run", exitUsage},
+		{"migrate up 0", exitUsage},
+		{"user", exitUsage},
+		{"user create", exitUsage},
+		{"user grant -email user@domain.tld", exitUsage},
+		{"articles", exitUsage},
+		{"purge-trash -retention -1h", exitUsage},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.args, func(t *testing.T) {
+			assert.Equal(t, tc.code, run(strings.Fields(tc.args)))
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package migration
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// DefaultLockTTL is the amount of time after which a lock that has not
+// been released is considered abandoned
+const DefaultLockTTL = 15 * time.Minute
+
+// lockID is the ID of the document used as a lock
+const lockID = "migrations"
+
+// ErrLocked is returned when the migrations are already being ran by
+// someone else
+var ErrLocked = errors.New("the migrations are locked by another instance")
+
+// lock represents the document preventing two instances from running the
+// migrations at the same time
+type lock struct {
+	ID        string    `bson:"_id"`
+	Owner     string    `bson:"owner"`
+	LockedAt  time.Time `bson:"locked_at"`
+	ExpiresAt time.Time `bson:"expires_at"`
+}
+
+// newOwner returns a string identifying the current process
+func newOwner() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return fmt.Sprintf("%s:%d:%s", host, os.Getpid(), bson.NewObjectId().Hex())
+}
+
+// acquireLock takes the lock, or returns ErrLocked if someone else has it.
+// An expired lock is taken over since its owner most likely crashed
+func (r *Runner) acquireLock() error {
+	now := time.Now()
+	l := &lock{
+		ID:        lockID,
+		Owner:     r.owner,
+		LockedAt:  now,
+		ExpiresAt: now.Add(r.LockTTL),
+	}
+
+	err := r.locks().Insert(l)
+	if err == nil {
+		return nil
+	}
+	if !mgo.IsDup(err) {
+		return err
+	}
+
+	err = r.locks().Update(bson.M{"_id": lockID, "expires_at": bson.M{"$lt": now}}, l)
+	if err == mgo.ErrNotFound {
+		return ErrLocked
+	}
+	return err
+}
+
+// releaseLock releases the lock, if it is still owned by the runner
+func (r *Runner) releaseLock() error {
+	err := r.locks().Remove(bson.M{"_id": lockID, "owner": r.owner})
+	if err == mgo.ErrNotFound {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,77 @@
+// Package migration runs versioned changes on the documents stored in
+// the database. The applied migrations are recorded in Mongo so each of
+// them only runs once
+package migration
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	mgo "gopkg.in/mgo.v2"
+)
+
+// Migration represents a change of the database. The version needs to be
+// unique, and is used to apply the migrations in order. Using the date the
+// migration has been written at (like 20161016120000) prevents collisions
+type Migration struct {
+	Version     int64
+	Description string
+
+	// Up applies the change
+	Up func(db *mgo.Database) error
+
+	// Down reverts the change. A nil Down means the migration cannot be
+	// reverted
+	Down func(db *mgo.Database) error
+}
+
+// String returns a human readable representation of the migration
+func (m *Migration) String() string {
+	return fmt.Sprintf("%d %s", m.Version, m.Description)
+}
+
+// Record represents a migration that has been applied
+type Record struct {
+	Version     int64     `bson:"_id"`
+	Description string    `bson:"description"`
+	AppliedAt   time.Time `bson:"applied_at"`
+}
+
+// Status represents the state of a migration
+type Status struct {
+	Migration *Migration
+
+	// AppliedAt contains the date the migration has been applied at, or the
+	// zero time if it is pending
+	AppliedAt time.Time
+}
+
+// IsApplied checks if the migration has been applied
+func (s *Status) IsApplied() bool {
+	return !s.AppliedAt.IsZero()
+}
+
+// Sort sorts the given migrations by version, and makes sure they can be
+// ran
+func Sort(migrations []*Migration) error {
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	for i, m := range migrations {
+		if m.Version <= 0 {
+			return fmt.Errorf("migration [%s] needs a positive version", m)
+		}
+
+		if m.Up == nil {
+			return fmt.Errorf("migration [%s] has no Up step", m)
+		}
+
+		if i > 0 && migrations[i-1].Version == m.Version {
+			return fmt.Errorf("version [%d] is used by more than one migration", m.Version)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,206 @@
+package migration
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	mgo "gopkg.in/mgo.v2"
+)
+
+// Runner applies and reverts migrations on a database
+type Runner struct {
+	// LockTTL is the amount of time after which the lock of a runner that
+	// did not release it can be taken by another runner
+	LockTTL time.Duration
+
+	db         *mgo.Database
+	migrations []*Migration
+	owner      string
+}
+
+// NewRunner returns a runner managing the given migrations
+func NewRunner(db *mgo.Database, migrations []*Migration) (*Runner, error) {
+	list := make([]*Migration, len(migrations))
+	copy(list, migrations)
+
+	if err := Sort(list); err != nil {
+		return nil, err
+	}
+
+	return &Runner{
+		LockTTL:    DefaultLockTTL,
+		db:         db,
+		migrations: list,
+		owner:      newOwner(),
+	}, nil
+}
+
+// records returns the collection containing the applied migrations
+func (r *Runner) records() *mgo.Collection {
+	return r.db.C("migration")
+}
+
+// locks returns the collection containing the lock of the runners
+func (r *Runner) locks() *mgo.Collection {
+	return r.db.C("migration_lock")
+}
+
+// applied returns the applied migrations indexed by version
+func (r *Runner) applied() (map[int64]*Record, error) {
+	records := []*Record{}
+	if err := r.records().Find(nil).All(&records); err != nil {
+		return nil, err
+	}
+
+	applied := make(map[int64]*Record, len(records))
+	for _, rec := range records {
+		applied[rec.Version] = rec
+	}
+	return applied, nil
+}
+
+// Status returns the state of all the migrations, sorted by version.
+// The applied migrations that are unknown to the runner are also returned,
+// without any step
+func (r *Runner) Status() ([]*Status, error) {
+	applied, err := r.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	list := []*Status{}
+	for _, m := range r.migrations {
+		s := &Status{Migration: m}
+		if rec, ok := applied[m.Version]; ok {
+			s.AppliedAt = rec.AppliedAt
+			delete(applied, m.Version)
+		}
+		list = append(list, s)
+	}
+
+	unknown := []*Status{}
+	for _, rec := range applied {
+		m := &Migration{Version: rec.Version, Description: rec.Description}
+		unknown = append(unknown, &Status{Migration: m, AppliedAt: rec.AppliedAt})
+	}
+	sort.Slice(unknown, func(i, j int) bool {
+		return unknown[i].Migration.Version < unknown[j].Migration.Version
+	})
+	list = append(list, unknown...)
+
+	return list, nil
+}
+
+// Pending returns the migrations that have not been applied yet, sorted by
+// version
+func (r *Runner) Pending() ([]*Migration, error) {
+	applied, err := r.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	pending := []*Migration{}
+	for _, m := range r.migrations {
+		if _, ok := applied[m.Version]; !ok {
+			pending = append(pending, m)
+		}
+	}
+	return pending, nil
+}
+
+// Up applies the given number of pending migrations, or all of them if
+// steps is lower than 1. The applied migrations are returned, even when an
+// error occurs. Nothing is changed in dry-run mode, but the migrations that
+// would have been applied are returned
+func (r *Runner) Up(steps int, dryRun bool) ([]*Migration, error) {
+	if !dryRun {
+		if err := r.acquireLock(); err != nil {
+			return nil, err
+		}
+		defer r.releaseLock()
+	}
+
+	pending, err := r.Pending()
+	if err != nil {
+		return nil, err
+	}
+
+	if steps > 0 && steps < len(pending) {
+		pending = pending[:steps]
+	}
+
+	if dryRun {
+		return pending, nil
+	}
+
+	done := []*Migration{}
+	for _, m := range pending {
+		if err := m.Up(r.db); err != nil {
+			return done, fmt.Errorf("migration [%s] failed: %s", m, err.Error())
+		}
+
+		rec := &Record{Version: m.Version, Description: m.Description, AppliedAt: time.Now()}
+		if err := r.records().Insert(rec); err != nil {
+			return done, fmt.Errorf("migration [%s] applied but could not be recorded: %s", m, err.Error())
+		}
+
+		done = append(done, m)
+	}
+
+	return done, nil
+}
+
+// Down reverts the given number of applied migrations, the most recent
+// first. The reverted migrations are returned, even when an error occurs.
+// Nothing is changed in dry-run mode, but the migrations that would have
+// been reverted are returned
+func (r *Runner) Down(steps int, dryRun bool) ([]*Migration, error) {
+	if steps < 1 {
+		return nil, fmt.Errorf("the number of migrations to revert needs to be greater than 0")
+	}
+
+	if !dryRun {
+		if err := r.acquireLock(); err != nil {
+			return nil, err
+		}
+		defer r.releaseLock()
+	}
+
+	applied, err := r.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	toRevert := []*Migration{}
+	for i := len(r.migrations) - 1; i >= 0 && len(toRevert) < steps; i-- {
+		if _, ok := applied[r.migrations[i].Version]; ok {
+			toRevert = append(toRevert, r.migrations[i])
+		}
+	}
+
+	for _, m := range toRevert {
+		if m.Down == nil {
+			return nil, fmt.Errorf("migration [%s] cannot be reverted", m)
+		}
+	}
+
+	if dryRun {
+		return toRevert, nil
+	}
+
+	done := []*Migration{}
+	for _, m := range toRevert {
+		if err := m.Down(r.db); err != nil {
+			return done, fmt.Errorf("reverting migration [%s] failed: %s", m, err.Error())
+		}
+
+		if err := r.records().RemoveId(m.Version); err != nil {
+			return done, fmt.Errorf("migration [%s] reverted but could not be unrecorded: %s", m, err.Error())
+		}
+
+		done = append(done, m)
+	}
+
+	return done, nil
+}
@@ -0,0 +1,283 @@
+package migration_test
+
+import (
+	"errors"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Nivl/api.melvin.la/api/app/testhelpers"
+	"github.com/Nivl/api.melvin.la/api/migration"
+	"github.com/stretchr/testify/assert"
+	mgo "gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// testDB is the database used by the tests of the package. It is not
+// shared with the other packages since the tests expect no migration to be
+// recorded
+var testDB *mgo.Database
+
+func TestMain(m *testing.M) {
+	a, err := testhelpers.NewApp()
+	if err != nil {
+		panic(err)
+	}
+	testDB = a.Session.DB(a.DB.Name + "_migration")
+
+	code := m.Run()
+	testDB.DropDatabase()
+	a.Destroy()
+	os.Exit(code)
+}
+
+// resetDB removes the records and the locks of the previous tests
+func resetDB(t *testing.T) {
+	for _, name := range []string{"migration", "migration_lock"} {
+		if _, err := testDB.C(name).RemoveAll(nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// newMigrations returns migrations adding their name to calls when they are
+// applied ("+1") or reverted ("-1")
+func newMigrations(calls *[]string, versions ...int64) []*migration.Migration {
+	list := []*migration.Migration{}
+	for _, v := range versions {
+		name := strconv.FormatInt(v, 10)
+		list = append(list, &migration.Migration{
+			Version:     v,
+			Description: "test migration",
+			Up: func(db *mgo.Database) error {
+				*calls = append(*calls, "+"+name)
+				return nil
+			},
+			Down: func(db *mgo.Database) error {
+				*calls = append(*calls, "-"+name)
+				return nil
+			},
+		})
+	}
+	return list
+}
+
+// versions returns the versions of the given migrations
+func versions(list []*migration.Migration) []int64 {
+	out := []int64{}
+	for _, m := range list {
+		out = append(out, m.Version)
+	}
+	return out
+}
+
+func newRunner(t *testing.T, migrations []*migration.Migration) *migration.Runner {
+	r, err := migration.NewRunner(testDB, migrations)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestRunnerSteps(t *testing.T) {
+	resetDB(t)
+	calls := []string{}
+	r := newRunner(t, newMigrations(&calls, 3, 1, 2))
+
+	done, err := r.Up(2, false)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{1, 2}, versions(done))
+
+	pending, err := r.Pending()
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{3}, versions(pending))
+
+	// A step lower than 1 applies everything that is left
+	done, err = r.Up(0, false)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{3}, versions(done))
+
+	done, err = r.Up(0, false)
+	assert.NoError(t, err)
+	assert.Empty(t, done)
+
+	// The most recent migrations are reverted first
+	done, err = r.Down(2, false)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{3, 2}, versions(done))
+
+	_, err = r.Down(0, false)
+	assert.Error(t, err)
+
+	done, err = r.Down(5, false)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{1}, versions(done))
+
+	assert.Equal(t, []string{"+1", "+2", "+3", "-3", "-2", "-1"}, calls)
+}
+
+func TestRunnerDryRun(t *testing.T) {
+	resetDB(t)
+	calls := []string{}
+	r := newRunner(t, newMigrations(&calls, 1, 2))
+
+	done, err := r.Up(0, true)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{1, 2}, versions(done))
+
+	pending, err := r.Pending()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(pending), "a dry-run should not record anything")
+
+	if _, err := r.Up(1, false); err != nil {
+		t.Fatal(err)
+	}
+
+	done, err = r.Down(1, true)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{1}, versions(done))
+
+	pending, err = r.Pending()
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{2}, versions(pending), "a dry-run should not unrecord anything")
+
+	// Only the real run called the migration
+	assert.Equal(t, []string{"+1"}, calls)
+}
+
+func TestRunnerRecords(t *testing.T) {
+	resetDB(t)
+	calls := []string{}
+	r := newRunner(t, newMigrations(&calls, 1, 2))
+
+	before := time.Now().Add(-time.Second)
+	if _, err := r.Up(1, false); err != nil {
+		t.Fatal(err)
+	}
+
+	rec := &migration.Record{}
+	if err := testDB.C("migration").FindId(int64(1)).One(rec); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "test migration", rec.Description)
+	assert.True(t, rec.AppliedAt.After(before))
+
+	// A migration that is recorded but unknown to the runner is reported
+	// at the end of the status
+	unknown := &migration.Record{Version: 5, Description: "removed", AppliedAt: time.Now()}
+	if err := testDB.C("migration").Insert(unknown); err != nil {
+		t.Fatal(err)
+	}
+
+	status, err := r.Status()
+	if assert.NoError(t, err) && assert.Equal(t, 3, len(status)) {
+		assert.True(t, status[0].IsApplied())
+		assert.False(t, status[1].IsApplied())
+		assert.Equal(t, int64(5), status[2].Migration.Version)
+		assert.True(t, status[2].IsApplied())
+		assert.Nil(t, status[2].Migration.Down)
+	}
+
+	// Reverting removes the record
+	if _, err := r.Down(1, false); err != nil {
+		t.Fatal(err)
+	}
+	count, err := testDB.C("migration").FindId(int64(1)).Count()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, count)
+}
+
+func TestRunnerFailure(t *testing.T) {
+	resetDB(t)
+	calls := []string{}
+	list := newMigrations(&calls, 1, 2, 3)
+	list[1].Up = func(db *mgo.Database) error {
+		return errors.New("failure")
+	}
+	list[0].Down = nil
+	r := newRunner(t, list)
+
+	// The migrations applied before the failure are kept
+	done, err := r.Up(0, false)
+	assert.Error(t, err)
+	assert.Equal(t, []int64{1}, versions(done))
+
+	pending, err := r.Pending()
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{2, 3}, versions(pending))
+
+	// Nothing is reverted if one of the migrations cannot be
+	done, err = r.Down(1, false)
+	assert.Error(t, err)
+	assert.Empty(t, done)
+	assert.Equal(t, []string{"+1"}, calls)
+}
+
+func TestRunnerLock(t *testing.T) {
+	now := time.Now()
+
+	testCases := []struct {
+		description string
+		expiresAt   time.Time
+		err         error
+	}{
+		{"Lock held by another runner", now.Add(time.Hour), migration.ErrLocked},
+		{"Expired lock", now.Add(-time.Minute), nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			resetDB(t)
+			calls := []string{}
+			r := newRunner(t, newMigrations(&calls, 1))
+
+			lock := bson.M{"_id": "migrations", "owner": "other", "locked_at": now.Add(-time.Hour), "expires_at": tc.expiresAt}
+			if err := testDB.C("migration_lock").Insert(lock); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err := r.Up(0, false)
+			assert.Equal(t, tc.err, err)
+
+			_, err = r.Down(1, false)
+			assert.Equal(t, tc.err, err)
+
+			// A dry-run doesn't need the lock
+			_, err = r.Up(0, true)
+			assert.NoError(t, err)
+
+			// The lock is released once a runner is done
+			count, err := testDB.C("migration_lock").Count()
+			assert.NoError(t, err)
+			if tc.err == nil {
+				assert.Equal(t, 0, count)
+			} else {
+				assert.Equal(t, 1, count)
+			}
+		})
+	}
+}
+
+func TestRunnerConcurrentRuns(t *testing.T) {
+	resetDB(t)
+	calls := []string{}
+	other := newRunner(t, newMigrations(&calls, 1))
+
+	// The second runner is started while the first one holds the lock
+	var otherErr error
+	r := newRunner(t, []*migration.Migration{
+		{
+			Version: 1,
+			Up: func(db *mgo.Database) error {
+				_, otherErr = other.Up(0, false)
+				return nil
+			},
+		},
+	})
+
+	_, err := r.Up(0, false)
+	assert.NoError(t, err)
+	assert.Equal(t, migration.ErrLocked, otherErr)
+	assert.Empty(t, calls)
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/Nivl/api.melvin.la/api/apierror"
+	"github.com/Nivl/api.melvin.la/api/migration"
+)
+
+// List of the output formats
+const (
+	outputHuman = "human"
+	outputJSON  = "json"
+)
+
+// List of the exit codes of the binary
+const (
+	exitOK      = 0
+	exitFailure = 1
+
+	// exitUsage is used when the command or its input is invalid
+	exitUsage = 2
+
+	// exitNotFound is used when the targeted document does not exist
+	exitNotFound = 3
+
+	// exitConflict is used when the change conflicts with the current data,
+	// or when the migrations are locked
+	exitConflict = 4
+)
+
+// usageError represents an error caused by an invalid use of a command
+type usageError struct {
+	error
+}
+
+// newUsageError returns a new usageError
+func newUsageError(message string, args ...interface{}) error {
+	return &usageError{fmt.Errorf(message, args...)}
+}
+
+// exitCode returns the exit code matching the given error
+func exitCode(err error) int {
+	if err == nil {
+		return exitOK
+	}
+
+	if _, ok := err.(*usageError); ok {
+		return exitUsage
+	}
+
+	if err == migration.ErrLocked {
+		return exitConflict
+	}
+
+	if e, ok := err.(apierror.Error); ok {
+		switch e.Code() {
+		case http.StatusBadRequest:
+			return exitUsage
+		case http.StatusNotFound:
+			return exitNotFound
+		case http.StatusConflict:
+			return exitConflict
+		}
+	}
+
+	return exitFailure
+}
+
+// output writes the results of the commands, either as text for humans, or
+// as JSON for the scripts
+type output struct {
+	json   bool
+	stdout io.Writer
+	stderr io.Writer
+}
+
+// newOutput returns an output using the given format. The human format
+// is used when an error is returned, so the error can be printed
+func newOutput(format string, stdout, stderr io.Writer) (*output, error) {
+	out := &output{stdout: stdout, stderr: stderr}
+
+	switch format {
+	case outputHuman:
+	case outputJSON:
+		out.json = true
+	default:
+		return out, newUsageError("unknown output format [%s]", format)
+	}
+
+	return out, nil
+}
+
+// Print writes the result of a command. data is written in JSON mode,
+// human is called otherwise
+func (o *output) Print(data interface{}, human func(w io.Writer)) {
+	if !o.json {
+		human(o.stdout)
+		return
+	}
+
+	enc := json.NewEncoder(o.stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(data); err != nil {
+		fmt.Fprintf(o.stderr, "could not encode the output: %s\n", err.Error())
+	}
+}
+
+// Error writes the given error on stderr
+func (o *output) Error(err error) {
+	if !o.json {
+		fmt.Fprintf(o.stderr, "error: %s\n", err.Error())
+		return
+	}
+
+	pld := struct {
+		Error    string `json:"error"`
+		ExitCode int    `json:"exit_code"`
+	}{err.Error(), exitCode(err)}
+
+	if err := json.NewEncoder(o.stderr).Encode(pld); err != nil {
+		fmt.Fprintf(o.stderr, "error: %s\n", pld.Error)
+	}
+}
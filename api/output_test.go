@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/Nivl/api.melvin.la/api/apierror"
+	"github.com/Nivl/api.melvin.la/api/migration"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExitCode(t *testing.T) {
+	testCases := []struct {
+		description string
+		err         error
+		code        int
+	}{
+		{"No error", nil, exitOK},
+		{"Usage error", newUsageError("invalid"), exitUsage},
+		{"Locked migrations", migration.ErrLocked, exitConflict},
+		{"Bad request", apierror.NewBadRequest("invalid"), exitUsage},
+		{"Invalid param", apierror.NewInvalidParam("email", "invalid"), exitUsage},
+		{"Not found", apierror.NewNotFound("not found"), exitNotFound},
+		{"Conflict", apierror.NewConflict("conflict"), exitConflict},
+		{"Other apierror", apierror.NewUnauthorized("unauthorized"), exitFailure},
+		{"Server error", apierror.NewServerError("failed"), exitFailure},
+		{"Other error", errors.New("failed"), exitFailure},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			assert.Equal(t, tc.code, exitCode(tc.err))
+		})
+	}
+}
+
+func TestNewOutput(t *testing.T) {
+	testCases := []struct {
+		description string
+		format      string
+		json        bool
+		valid       bool
+	}{
+		{"Human", outputHuman, false, true},
+		{"JSON", outputJSON, true, true},
+		{"Unknown format", "xml", false, false},
+		{"Empty format", "", false, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			out, err := newOutput(tc.format, &stdout, &stderr)
+			if tc.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Equal(t, exitUsage, exitCode(err))
+			}
+
+			// The output is always usable, to be able to print the error
+			if !assert.NotNil(t, out) {
+				return
+			}
+			assert.Equal(t, tc.json, out.json)
+
+			out.Print(map[string]int{"count": 2}, func(w io.Writer) {
+				io.WriteString(w, "2 items\n")
+			})
+			out.Error(apierror.NewNotFound("not found"))
+
+			if tc.json {
+				assert.JSONEq(t, `{"count": 2}`, stdout.String())
+				assert.JSONEq(t, `{"error": "not found", "exit_code": 3}`, stderr.String())
+			} else {
+				assert.Equal(t, "2 items\n", stdout.String())
+				assert.Equal(t, "error: not found\n", stderr.String())
+			}
+		})
+	}
+}
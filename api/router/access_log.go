@@ -0,0 +1,42 @@
+package router
+
+import (
+	"time"
+
+	"github.com/Nivl/api.melvin.la/api/logger"
+	"github.com/gorilla/mux"
+)
+
+// logAccess logs the request once it has been handled. The request ID is
+// added by the logger of the request
+func (req *Request) logAccess(start time.Time, w *responseWriter) {
+	fields := logger.Fields{
+		"method":      req.Request.Method,
+		"route":       req.routeTemplate(),
+		"status":      w.Status(),
+		"size":        w.Size(),
+		"duration_ms": float64(time.Since(start)) / float64(time.Millisecond),
+		"ip":          req.ClientIP(),
+	}
+
+	if req.User != nil {
+		fields["user_id"] = req.User.UserID()
+	}
+
+	req.Logger.With(fields).Info("request handled")
+}
+
+// routeTemplate returns the template of the route matching the request, like
+// /blog/articles/{id}
+func (req *Request) routeTemplate() string {
+	if route := mux.CurrentRoute(req.Request); route != nil {
+		if tpl, err := route.GetPathTemplate(); err == nil {
+			return tpl
+		}
+	}
+
+	if req.Endpoint != nil {
+		return req.Endpoint.Path
+	}
+	return req.Request.URL.Path
+}
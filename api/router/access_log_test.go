@@ -0,0 +1,151 @@
+package router_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Nivl/api.melvin.la/api/app"
+	"github.com/Nivl/api.melvin.la/api/logger"
+	"github.com/Nivl/api.melvin.la/api/router"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+// newLoggedApp returns an app logging its entries as JSON to buf
+func newLoggedApp(buf *bytes.Buffer) *app.Context {
+	a := &app.Context{
+		Logger: logger.New(logger.NewWriterSink(buf), &logger.JSONEncoder{}, logger.LevelInfo),
+	}
+	a.Params.TrustedProxies = []string{"10.0.0.1"}
+	return a
+}
+
+func TestAccessLog(t *testing.T) {
+	testCases := []struct {
+		description string
+		auth        router.RouteAuth
+		handler     router.RouteHandler
+		status      int
+		size        int
+		userID      interface{}
+	}{
+		{
+			"Anonymous",
+			nil,
+			func(req *router.Request) { req.Response.Write([]byte("hello")) },
+			http.StatusOK, 5, nil,
+		},
+		{
+			"Logged user",
+			func(req *router.Request) (router.User, error) { return &testUser{}, nil },
+			func(req *router.Request) { req.NoContent() },
+			http.StatusNoContent, 0, "user",
+		},
+		{
+			"Panic",
+			nil,
+			func(req *router.Request) { panic("oops") },
+			http.StatusInternalServerError, -1, nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			var buf bytes.Buffer
+			r := mux.NewRouter()
+			router.NewGroup(r, newLoggedApp(&buf)).Activate(router.Endpoints{
+				{Verb: "GET", Path: "/items/{id}", Auth: tc.auth, Handler: tc.handler},
+			})
+
+			req := httptest.NewRequest("GET", "/items/42", nil)
+			req.RemoteAddr = "10.0.0.1:1234"
+			req.Header.Set("X-Forwarded-For", "5.6.7.8")
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+
+			// The access log is the last entry
+			var entry map[string]interface{}
+			lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+			if !assert.NoError(t, json.Unmarshal(lines[len(lines)-1], &entry)) {
+				return
+			}
+
+			assert.Equal(t, "request handled", entry["message"])
+			assert.Equal(t, rec.Header().Get("X-Request-Id"), entry["request_id"])
+			assert.Equal(t, "GET", entry["method"])
+			assert.Equal(t, "/items/{id}", entry["route"])
+			assert.Equal(t, "5.6.7.8", entry["ip"])
+			assert.Equal(t, float64(tc.status), entry["status"])
+			assert.Equal(t, tc.userID, entry["user_id"])
+			assert.Contains(t, entry, "duration_ms")
+			if tc.size >= 0 {
+				assert.Equal(t, float64(tc.size), entry["size"])
+			}
+		})
+	}
+}
+
+// hijackRecorder is a ResponseRecorder supporting hijacking
+type hijackRecorder struct {
+	*httptest.ResponseRecorder
+	hijacked bool
+}
+
+func (w *hijackRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.hijacked = true
+	return nil, nil, nil
+}
+
+func TestResponseWriterInterfaces(t *testing.T) {
+	var buf bytes.Buffer
+	r := mux.NewRouter()
+	router.NewGroup(r, newLoggedApp(&buf)).Activate(router.Endpoints{
+		{
+			Verb: "GET",
+			Path: "/stream",
+			Handler: func(req *router.Request) {
+				f, ok := req.Response.(http.Flusher)
+				if assert.True(t, ok, "the response should be an http.Flusher") {
+					f.Flush()
+				}
+
+				h, ok := req.Response.(http.Hijacker)
+				if assert.True(t, ok, "the response should be an http.Hijacker") {
+					_, _, err := h.Hijack()
+					assert.NoError(t, err)
+				}
+			},
+		},
+	})
+
+	rec := &hijackRecorder{ResponseRecorder: httptest.NewRecorder()}
+	r.ServeHTTP(rec, httptest.NewRequest("GET", "/stream", nil))
+
+	assert.True(t, rec.Flushed)
+	assert.True(t, rec.hijacked)
+}
+
+func TestResponseWriterHijackUnsupported(t *testing.T) {
+	var buf bytes.Buffer
+	r := mux.NewRouter()
+	router.NewGroup(r, newLoggedApp(&buf)).Activate(router.Endpoints{
+		{
+			Verb: "GET",
+			Path: "/",
+			Handler: func(req *router.Request) {
+				_, _, err := req.Response.(http.Hijacker).Hijack()
+				assert.Error(t, err)
+				req.NoContent()
+			},
+		},
+	})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+}
@@ -0,0 +1,81 @@
+package router
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// ClientIP returns the IP address of the client making the request.
+// The X-Forwarded-For and X-Real-Ip headers are only used when the request
+// comes from one of the given proxies
+func ClientIP(req *http.Request, proxies []string) string {
+	ip := req.RemoteAddr
+	if host, _, err := net.SplitHostPort(req.RemoteAddr); err == nil {
+		ip = host
+	}
+
+	if !isTrustedProxy(ip, proxies) {
+		return ip
+	}
+
+	forwarded := req.Header.Get("X-Forwarded-For")
+	if forwarded == "" {
+		if realIP := strings.TrimSpace(req.Header.Get("X-Real-Ip")); realIP != "" {
+			return realIP
+		}
+		return ip
+	}
+
+	// Each proxy appends the address it received the request from, so we
+	// walk the list backward until we find an address we don't trust
+	addrs := strings.Split(forwarded, ",")
+	for i := len(addrs) - 1; i >= 0; i-- {
+		addr := strings.TrimSpace(addrs[i])
+		if addr == "" {
+			continue
+		}
+
+		ip = addr
+		if !isTrustedProxy(addr, proxies) {
+			break
+		}
+	}
+
+	return ip
+}
+
+// isTrustedProxy checks if the given IP matches one of the proxies. A proxy
+// can either be an IP address or a CIDR
+func isTrustedProxy(ip string, proxies []string) bool {
+	parsedIP := net.ParseIP(ip)
+	if parsedIP == nil {
+		return false
+	}
+
+	for _, proxy := range proxies {
+		if strings.Contains(proxy, "/") {
+			_, network, err := net.ParseCIDR(proxy)
+			if err == nil && network.Contains(parsedIP) {
+				return true
+			}
+			continue
+		}
+
+		if proxyIP := net.ParseIP(proxy); proxyIP != nil && proxyIP.Equal(parsedIP) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ClientIP returns the IP address of the client making the request, using
+// the TrustedProxies param of the app
+func (req *Request) ClientIP() string {
+	var proxies []string
+	if req.App != nil {
+		proxies = req.App.Params.TrustedProxies
+	}
+	return ClientIP(req.Request, proxies)
+}
@@ -0,0 +1,50 @@
+package router_test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Nivl/api.melvin.la/api/router"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientIP(t *testing.T) {
+	proxies := []string{"10.0.0.1", "192.168.0.0/16", "::1"}
+
+	testCases := []struct {
+		description string
+		remoteAddr  string
+		forwarded   string
+		realIP      string
+		expected    string
+	}{
+		{"No proxy", "1.2.3.4:1234", "", "", "1.2.3.4"},
+		{"Untrusted proxy", "1.2.3.4:1234", "5.6.7.8", "9.9.9.9", "1.2.3.4"},
+		{"Trusted proxy", "10.0.0.1:1234", "5.6.7.8", "", "5.6.7.8"},
+		{"Trusted CIDR", "192.168.1.12:1234", "5.6.7.8", "", "5.6.7.8"},
+		{"Trusted IPv6 proxy", "[::1]:1234", "5.6.7.8", "", "5.6.7.8"},
+		{"Chain of trusted proxies", "10.0.0.1:1234", "5.6.7.8, 192.168.4.4", "", "5.6.7.8"},
+		{"Spoofed chain", "10.0.0.1:1234", "6.6.6.6, 5.6.7.8, 192.168.4.4", "", "5.6.7.8"},
+		{"Only trusted proxies", "10.0.0.1:1234", "192.168.4.4, 10.0.0.1", "", "192.168.4.4"},
+		{"Empty entries", "10.0.0.1:1234", "5.6.7.8, ,", "", "5.6.7.8"},
+		{"X-Real-Ip", "10.0.0.1:1234", "", " 5.6.7.8 ", "5.6.7.8"},
+		{"X-Forwarded-For over X-Real-Ip", "10.0.0.1:1234", "5.6.7.8", "9.9.9.9", "5.6.7.8"},
+		{"Trusted proxy without headers", "10.0.0.1:1234", "", "", "10.0.0.1"},
+		{"No port", "1.2.3.4", "", "", "1.2.3.4"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			req.RemoteAddr = tc.remoteAddr
+			if tc.forwarded != "" {
+				req.Header.Set("X-Forwarded-For", tc.forwarded)
+			}
+			if tc.realIP != "" {
+				req.Header.Set("X-Real-Ip", tc.realIP)
+			}
+
+			assert.Equal(t, tc.expected, router.ClientIP(req, proxies))
+		})
+	}
+}
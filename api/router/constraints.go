@@ -0,0 +1,192 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// List of the constraints that can be used in the params tag.
+//
+//	min_len=N    The value must contain at least N characters
+//	max_len=N    The value must contain at most N characters
+//	min=N        The value must be a number greater or equal to N
+//	max=N        The value must be a number lower or equal to N
+//	enum=a|b|c   The value must be one of the given values
+//	regexp=expr  The value must match expr. Must be the last option of the tag
+//	email        The value must be an email address
+//	url          The value must be an absolute http(s) URL
+//	uuid         The value must be a UUID
+//	objectid     The value must be a Mongo ObjectId
+//	slug         The value must be a slug (lowercase letters, digits and dashes)
+const (
+	ConstraintMinLen   = "min_len"
+	ConstraintMaxLen   = "max_len"
+	ConstraintMin      = "min"
+	ConstraintMax      = "max"
+	ConstraintEnum     = "enum"
+	ConstraintRegexp   = "regexp"
+	ConstraintEmail    = "email"
+	ConstraintURL      = "url"
+	ConstraintUUID     = "uuid"
+	ConstraintObjectID = "objectid"
+	ConstraintSlug     = "slug"
+)
+
+var (
+	uuidRegexp     = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	objectIDRegexp = regexp.MustCompile(`^[0-9a-fA-F]{24}$`)
+	slugRegexp     = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+	// regexps contains the compiled expressions of the regexp constraints,
+	// since the tags are parsed for every request
+	regexps = struct {
+		sync.RWMutex
+		list map[string]*regexp.Regexp
+	}{list: map[string]*regexp.Regexp{}}
+)
+
+// Constraint represents a rule a param has to satisfy
+type Constraint struct {
+	Name  string
+	Arg   string
+	check func(value string) error
+}
+
+// Check returns an error describing why the value does not satisfy the
+// constraint, or nil
+func (c Constraint) Check(value string) error {
+	return c.check(value)
+}
+
+// NewConstraint returns the constraint matching the given name
+func NewConstraint(name string, arg string) (Constraint, error) {
+	c := Constraint{Name: name, Arg: arg}
+
+	switch name {
+	case ConstraintMinLen, ConstraintMaxLen:
+		limit, err := strconv.Atoi(arg)
+		if err != nil {
+			return c, fmt.Errorf("%s expects an integer, got [%s]", name, arg)
+		}
+
+		c.check = func(value string) error {
+			length := utf8.RuneCountInString(value)
+			if name == ConstraintMinLen && length < limit {
+				return fmt.Errorf("must contain at least %d characters", limit)
+			}
+			if name == ConstraintMaxLen && length > limit {
+				return fmt.Errorf("must contain at most %d characters", limit)
+			}
+			return nil
+		}
+	case ConstraintMin, ConstraintMax:
+		limit, err := strconv.ParseFloat(arg, 64)
+		if err != nil {
+			return c, fmt.Errorf("%s expects a number, got [%s]", name, arg)
+		}
+
+		c.check = func(value string) error {
+			v, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return errors.New("must be a number")
+			}
+			if name == ConstraintMin && v < limit {
+				return fmt.Errorf("must be greater than or equal to %s", arg)
+			}
+			if name == ConstraintMax && v > limit {
+				return fmt.Errorf("must be lower than or equal to %s", arg)
+			}
+			return nil
+		}
+	case ConstraintEnum:
+		if arg == "" {
+			return c, errors.New("enum expects at least one value")
+		}
+
+		allowed := strings.Split(arg, "|")
+		c.check = func(value string) error {
+			for _, v := range allowed {
+				if v == value {
+					return nil
+				}
+			}
+			return fmt.Errorf("must be one of [%s]", strings.Join(allowed, ", "))
+		}
+	case ConstraintRegexp:
+		re, err := compileRegexp(arg)
+		if err != nil {
+			return c, err
+		}
+
+		c.check = func(value string) error {
+			if !re.MatchString(value) {
+				return fmt.Errorf("must match %s", arg)
+			}
+			return nil
+		}
+	case ConstraintEmail:
+		c.check = func(value string) error {
+			addr, err := mail.ParseAddress(value)
+			if err != nil || addr.Address != value {
+				return errors.New("must be an email address")
+			}
+			return nil
+		}
+	case ConstraintURL:
+		c.check = func(value string) error {
+			u, err := url.ParseRequestURI(value)
+			if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+				return errors.New("must be an http or https URL")
+			}
+			return nil
+		}
+	case ConstraintUUID:
+		c.check = matchRegexp(uuidRegexp, "must be a UUID")
+	case ConstraintObjectID:
+		c.check = matchRegexp(objectIDRegexp, "must be an ObjectId")
+	case ConstraintSlug:
+		c.check = matchRegexp(slugRegexp, "must only contain lowercase letters, digits and dashes")
+	default:
+		return c, fmt.Errorf("unknown constraint [%s]", name)
+	}
+
+	return c, nil
+}
+
+// matchRegexp returns a check function that fails with the given message
+// when the value does not match re
+func matchRegexp(re *regexp.Regexp, message string) func(string) error {
+	return func(value string) error {
+		if !re.MatchString(value) {
+			return errors.New(message)
+		}
+		return nil
+	}
+}
+
+// compileRegexp compiles the given expression, or returns it from the cache
+func compileRegexp(expr string) (*regexp.Regexp, error) {
+	regexps.RLock()
+	re, found := regexps.list[expr]
+	regexps.RUnlock()
+	if found {
+		return re, nil
+	}
+
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regexp [%s]: %s", expr, err.Error())
+	}
+
+	regexps.Lock()
+	regexps.list[expr] = re
+	regexps.Unlock()
+	return re, nil
+}
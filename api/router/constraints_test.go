@@ -0,0 +1,146 @@
+package router_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Nivl/api.melvin.la/api/router"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConstraints(t *testing.T) {
+	testCases := []struct {
+		description string
+		name        string
+		arg         string
+		value       string
+		valid       bool
+	}{
+		{"min_len reached", router.ConstraintMinLen, "3", "abc", true},
+		{"min_len counts runes", router.ConstraintMinLen, "3", "été", true},
+		{"min_len not reached", router.ConstraintMinLen, "3", "ab", false},
+		{"min_len on empty value", router.ConstraintMinLen, "1", "", false},
+		{"max_len reached", router.ConstraintMaxLen, "3", "abc", true},
+		{"max_len counts runes", router.ConstraintMaxLen, "3", "ééé", true},
+		{"max_len exceeded", router.ConstraintMaxLen, "3", "abcd", false},
+		{"min reached", router.ConstraintMin, "1", "1", true},
+		{"min with a float", router.ConstraintMin, "0.5", "0.6", true},
+		{"min not reached", router.ConstraintMin, "1", "0", false},
+		{"min with a negative number", router.ConstraintMin, "-10", "-11", false},
+		{"min on a text", router.ConstraintMin, "1", "one", false},
+		{"max reached", router.ConstraintMax, "100", "100", true},
+		{"max exceeded", router.ConstraintMax, "100", "100.1", false},
+		{"max on empty value", router.ConstraintMax, "100", "", false},
+		{"enum value", router.ConstraintEnum, "asc|desc", "desc", true},
+		{"enum unknown value", router.ConstraintEnum, "asc|desc", "ASC", false},
+		{"enum empty value", router.ConstraintEnum, "asc|desc", "", false},
+		{"regexp match", router.ConstraintRegexp, "^[a-z]+$", "abc", true},
+		{"regexp mismatch", router.ConstraintRegexp, "^[a-z]+$", "abc1", false},
+		{"regexp with a comma", router.ConstraintRegexp, "^a{1,2}$", "aa", true},
+		{"email", router.ConstraintEmail, "", "user@domain.tld", true},
+		{"email with a name", router.ConstraintEmail, "", "User <user@domain.tld>", false},
+		{"email without domain", router.ConstraintEmail, "", "user", false},
+		{"url http", router.ConstraintURL, "", "http://domain.tld/path?q=1", true},
+		{"url https", router.ConstraintURL, "", "https://domain.tld", true},
+		{"url other scheme", router.ConstraintURL, "", "ftp://domain.tld", false},
+		{"url relative", router.ConstraintURL, "", "/path", false},
+		{"url without host", router.ConstraintURL, "", "http://", false},
+		{"uuid", router.ConstraintUUID, "", "0f8fad5b-d9cb-469f-a165-70867728950e", true},
+		{"uuid uppercase", router.ConstraintUUID, "", "0F8FAD5B-D9CB-469F-A165-70867728950E", true},
+		{"uuid without dashes", router.ConstraintUUID, "", "0f8fad5bd9cb469fa16570867728950e", false},
+		{"uuid too short", router.ConstraintUUID, "", "0f8fad5b-d9cb-469f-a165-70867728950", false},
+		{"objectid", router.ConstraintObjectID, "", "58a4b9e0e2e4b3a7c5d6f7a8", true},
+		{"objectid invalid", router.ConstraintObjectID, "", "58a4b9e0e2e4b3a7c5d6f7az", false},
+		{"slug", router.ConstraintSlug, "", "my-slug-2", true},
+		{"slug with uppercase", router.ConstraintSlug, "", "My-slug", false},
+		{"slug with double dash", router.ConstraintSlug, "", "my--slug", false},
+		{"slug with trailing dash", router.ConstraintSlug, "", "my-slug-", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			c, err := router.NewConstraint(tc.name, tc.arg)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			err = c.Check(tc.value)
+			if tc.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestNewConstraintInvalid(t *testing.T) {
+	testCases := []struct {
+		description string
+		name        string
+		arg         string
+	}{
+		{"Unknown constraint", "unknown", ""},
+		{"min_len without limit", router.ConstraintMinLen, ""},
+		{"max_len with a float", router.ConstraintMaxLen, "2.5"},
+		{"min with a text", router.ConstraintMin, "one"},
+		{"max without limit", router.ConstraintMax, ""},
+		{"enum without values", router.ConstraintEnum, ""},
+		{"Invalid regexp", router.ConstraintRegexp, "[a-z"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			_, err := router.NewConstraint(tc.name, tc.arg)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestParamOptionsConstraints(t *testing.T) {
+	testCases := []struct {
+		description string
+		tag         string
+		constraints []string
+		valid       bool
+	}{
+		{"No constraints", `params:"required,trim"`, nil, true},
+		{"Several constraints", `params:"trim,min_len=2,max_len=10,slug"`, []string{"min_len", "max_len", "slug"}, true},
+		{"Regexp using the rest of the tag", `params:"required,regexp=^a{1,2}$"`, []string{"regexp"}, true},
+		{"Unknown constraint", `params:"required,unknown"`, nil, false},
+		{"Invalid arg", `params:"max_len=ten"`, nil, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			tag := reflect.StructTag(tc.tag)
+			opts, err := router.NewParamOptions(&tag)
+			if !tc.valid {
+				assert.Error(t, err)
+				return
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			var names []string
+			for _, c := range opts.Constraints {
+				names = append(names, c.Name)
+			}
+			assert.Equal(t, tc.constraints, names)
+		})
+	}
+}
+
+func TestParamOptionsValidate(t *testing.T) {
+	tag := reflect.StructTag(`json:"code" params:"min_len=2,max_len=4,regexp=^[a-z,]+$"`)
+	opts, err := router.NewParamOptions(&tag)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.NoError(t, opts.Validate("a,b"))
+	assert.Error(t, opts.Validate("a"), "min_len should be checked")
+	assert.Error(t, opts.Validate("abcde"), "max_len should be checked")
+	assert.Error(t, opts.Validate("AB"), "regexp should be checked")
+}
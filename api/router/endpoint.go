@@ -1,6 +1,23 @@
 package router
 
-type RouteAuth func(*Request) bool
+import "github.com/Nivl/api.melvin.la/api/apierror"
+
+// DefaultMaxBodySize contains the maximum size of a request body, in bytes,
+// for the endpoints that don't set MaxBodySize
+const DefaultMaxBodySize = 1 << 20
+
+// User represents an authenticated user making a request
+type User interface {
+	// UserID returns the unique identifier of the user
+	UserID() string
+
+	// HasPermission checks if the user has the given permission
+	HasPermission(permission string) bool
+}
+
+// RouteAuth returns the user making the request. A nil User and a nil error
+// are returned if the request is anonymous.
+type RouteAuth func(*Request) (User, error)
 type RouteHandler func(*Request)
 
 // Endpoint represents an HTTP endpoint
@@ -10,4 +27,50 @@ type Endpoint struct {
 	Auth    RouteAuth
 	Handler RouteHandler
 	Params  interface{}
+
+	// AuthRequired means the endpoint cannot be accessed anonymously
+	AuthRequired bool
+
+	// Permissions contains all the permissions a user needs to access the
+	// endpoint. Setting permissions implies AuthRequired
+	Permissions []string
+
+	// DisallowUnknownFields means the request should fail with a Bad Request
+	// if its JSON body contains a field that is not part of Params
+	DisallowUnknownFields bool
+
+	// MaxBodySize contains the maximum size of the request body, in bytes.
+	// DefaultMaxBodySize is used if empty
+	MaxBodySize int64
+
+	// Middlewares contains the middlewares to run on this endpoint only. They
+	// are ran once the user is authenticated and the params are parsed
+	Middlewares []Middleware
+}
+
+// maxBodySize returns the maximum size of the request body, in bytes
+func (e *Endpoint) maxBodySize() int64 {
+	if e.MaxBodySize > 0 {
+		return e.MaxBodySize
+	}
+	return DefaultMaxBodySize
+}
+
+// checkAccess returns an error if the given user is not allowed to
+// access the endpoint
+func (e *Endpoint) checkAccess(u User) error {
+	if u == nil {
+		if e.AuthRequired || len(e.Permissions) > 0 {
+			return apierror.NewUnauthorized("authentication required")
+		}
+		return nil
+	}
+
+	for _, permission := range e.Permissions {
+		if !u.HasPermission(permission) {
+			return apierror.NewForbidden("permission [%s] required", permission)
+		}
+	}
+
+	return nil
 }
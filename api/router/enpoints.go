@@ -3,49 +3,100 @@ package router
 import (
 	"net/http"
 	"reflect"
+	"time"
 
+	"github.com/Nivl/api.melvin.la/api/app"
+	"github.com/Nivl/api.melvin.la/api/logger"
 	"github.com/gorilla/mux"
 	uuid "github.com/satori/go.uuid"
 )
 
 type Endpoints []*Endpoint
 
-func (endpoints Endpoints) Activate(router *mux.Router) {
+// Activate registers all the endpoints on the given router, to be handled
+// by the given app. The middlewares are ran on every endpoints, before the
+// user is authenticated. Group.Activate() can be used to give a subrouter
+// its own middlewares
+func (endpoints Endpoints) Activate(router *mux.Router, a *app.Context, middlewares ...Middleware) {
 	for _, endpoint := range endpoints {
 		router.
 			Methods(endpoint.Verb).
 			Path(endpoint.Path).
-			Handler(Handler(endpoint))
+			Handler(Handler(endpoint, a, middlewares...))
 	}
 }
 
-// Handler makes it possible to use a RouteHandler where a http.Handler is required
-func Handler(e *Endpoint) http.Handler {
+// Handler makes it possible to use a RouteHandler where a http.Handler is required.
+// The lifecycle of a request is the following:
+//
+//   - The request is given an ID and its panics are recovered
+//   - The database session of the request is closed once handled
+//   - The request is logged once handled
+//   - The given middlewares are ran (the ones of the router and subrouters)
+//   - The user is authenticated and its permissions are checked
+//   - The params are parsed
+//   - The middlewares of the endpoint are ran, with req.User and req.Params set
+//   - The handler of the endpoint is called
+func Handler(e *Endpoint, a *app.Context, middlewares ...Middleware) http.Handler {
+	handler := Middlewares(middlewares).
+		Append(authenticate(e), parseParams(e)).
+		Append(e.Middlewares...).
+		Then(e.Handler)
+
 	HTTPHandler := func(resWriter http.ResponseWriter, req *http.Request) {
+		start := time.Now()
+		writer := &responseWriter{ResponseWriter: resWriter}
+
 		request := &Request{
 			ID:       uuid.NewV4().String()[:8],
 			Request:  req,
-			Response: resWriter,
+			Response: writer,
+			Endpoint: e,
+			App:      a,
 		}
 
+		// A nil logger falls back to the default one
+		request.Logger = a.Logger.With(logger.Fields{"request_id": request.ID})
+		req.Body = http.MaxBytesReader(resWriter, req.Body, e.maxBodySize())
+
 		request.Response.Header().Set("X-Request-Id", request.ID)
 
-		if e.Params != nil {
-			// We give request.Params the same type as e.Params
-			request.Params = reflect.New(reflect.TypeOf(e.Params).Elem()).Interface()
-			if err := request.ParseParams(); err != nil {
-				request.Error(err)
-				return
+		// The access log needs to be written after the panics are handled
+		defer request.closeSession()
+		defer request.logAccess(start, writer)
+		defer request.handlePanic()
+
+		handler(request)
+	}
+
+	return http.HandlerFunc(HTTPHandler)
+}
+
+// authenticate returns a middleware that retrieves the user making the
+// request, and makes sure they can access the endpoint
+func authenticate(e *Endpoint) Middleware {
+	return Before(func(request *Request) error {
+		if e.Auth != nil {
+			user, err := e.Auth(request)
+			if err != nil {
+				return err
 			}
+			request.User = user
 		}
 
-		defer request.handlePanic()
+		return e.checkAccess(request.User)
+	})
+}
 
-		accessGranted := e.Auth == nil || e.Auth(request)
-		if accessGranted {
-			e.Handler(request)
+// parseParams returns a middleware that parses the params of the endpoint
+func parseParams(e *Endpoint) Middleware {
+	return Before(func(request *Request) error {
+		if e.Params == nil {
+			return nil
 		}
-	}
 
-	return http.HandlerFunc(HTTPHandler)
+		// We give request.Params the same type as e.Params
+		request.Params = reflect.New(reflect.TypeOf(e.Params).Elem()).Interface()
+		return request.ParseParams()
+	})
 }
@@ -0,0 +1,35 @@
+package router
+
+import (
+	"mime/multipart"
+)
+
+// File represents a file uploaded using a multipart form.
+// Params using the "file" source must be of type *File or []*File
+type File struct {
+	// Name contains the name of the file, as sent by the client
+	Name string
+
+	// Size contains the size of the file in bytes
+	Size int64
+
+	// ContentType contains the Content-Type of the file, as sent by the client
+	ContentType string
+
+	header *multipart.FileHeader
+}
+
+func newFile(header *multipart.FileHeader) *File {
+	return &File{
+		Name:        header.Filename,
+		Size:        header.Size,
+		ContentType: header.Header.Get("Content-Type"),
+		header:      header,
+	}
+}
+
+// Open returns a reader on the content of the file. The reader must be
+// closed by the caller
+func (f *File) Open() (multipart.File, error) {
+	return f.header.Open()
+}
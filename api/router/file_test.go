@@ -0,0 +1,292 @@
+package router_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Nivl/api.melvin.la/api/apierror"
+	"github.com/Nivl/api.melvin.la/api/router"
+	"github.com/stretchr/testify/assert"
+)
+
+// callEndpoint sends r to the handler of e, and returns the response
+func callEndpoint(e *router.Endpoint, r *http.Request) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	router.Handler(e, newLoggedApp(&bytes.Buffer{})).ServeHTTP(rec, r)
+	return rec
+}
+
+// decodeError returns the error sent in the given response
+func decodeError(t *testing.T, rec *httptest.ResponseRecorder) *apierror.PayloadError {
+	pld := &apierror.Payload{}
+	if err := json.NewDecoder(rec.Body).Decode(pld); err != nil {
+		t.Fatalf("could not decode the error: %s", err)
+	}
+	if pld.Error == nil {
+		t.Fatalf("no error in the response")
+	}
+	return pld.Error
+}
+
+// testFile represents a file to upload in a multipart body
+type testFile struct {
+	field       string
+	name        string
+	contentType string
+	content     string
+}
+
+// newMultipartRequest returns a POST request sending the given values and
+// files in a multipart body
+func newMultipartRequest(t *testing.T, values map[string]string, files []testFile) *http.Request {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	for name, value := range values {
+		if err := writer.WriteField(name, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, f := range files {
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Disposition", `form-data; name="`+f.field+`"; filename="`+f.name+`"`)
+		header.Set("Content-Type", f.contentType)
+
+		part, err := writer.CreatePart(header)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.WriteString(part, f.content); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r := httptest.NewRequest("POST", "/", &body)
+	r.Header.Set("Content-Type", writer.FormDataContentType())
+	return r
+}
+
+type uploadParams struct {
+	Title       string         `from:"form" json:"title"`
+	Avatar      *router.File   `from:"file" json:"avatar" params:"required,max_size=8B,regexp=^image/"`
+	Attachments []*router.File `from:"file" json:"attachments"`
+}
+
+// uploadResult contains the data of the files received by the upload
+// endpoint
+type uploadResult struct {
+	Title       string   `json:"title"`
+	Name        string   `json:"name"`
+	Size        int64    `json:"size"`
+	ContentType string   `json:"content_type"`
+	Content     string   `json:"content"`
+	Attachments []string `json:"attachments"`
+}
+
+func handlerUpload(req *router.Request) {
+	params := req.Params.(*uploadParams)
+
+	f, err := params.Avatar.Open()
+	if err != nil {
+		req.Error(err)
+		return
+	}
+	defer f.Close()
+
+	content, err := io.ReadAll(f)
+	if err != nil {
+		req.Error(err)
+		return
+	}
+
+	res := &uploadResult{
+		Title:       params.Title,
+		Name:        params.Avatar.Name,
+		Size:        params.Avatar.Size,
+		ContentType: params.Avatar.ContentType,
+		Content:     string(content),
+	}
+	for _, attachment := range params.Attachments {
+		res.Attachments = append(res.Attachments, attachment.Name)
+	}
+	req.Ok(res)
+}
+
+func TestFileParams(t *testing.T) {
+	e := &router.Endpoint{
+		Verb:    "POST",
+		Path:    "/",
+		Handler: handlerUpload,
+		Params:  &uploadParams{},
+	}
+
+	avatar := testFile{"avatar", "me.png", "image/png", "png-data"}
+
+	testCases := []struct {
+		description string
+		values      map[string]string
+		files       []testFile
+		code        int
+		errCode     string
+		expected    *uploadResult
+	}{
+		{
+			"Single file",
+			map[string]string{"title": "hello"},
+			[]testFile{avatar},
+			http.StatusOK, "",
+			&uploadResult{Title: "hello", Name: "me.png", Size: 8, ContentType: "image/png", Content: "png-data"},
+		},
+		{
+			"List of files",
+			nil,
+			[]testFile{avatar, {"attachments", "a.txt", "text/plain", "a"}, {"attachments", "b.txt", "text/plain", "b"}},
+			http.StatusOK, "",
+			&uploadResult{Name: "me.png", Size: 8, ContentType: "image/png", Content: "png-data", Attachments: []string{"a.txt", "b.txt"}},
+		},
+		{
+			"Missing required file",
+			map[string]string{"title": "hello"},
+			[]testFile{{"attachments", "a.txt", "text/plain", "a"}},
+			http.StatusBadRequest, apierror.TypeMissingParam,
+			nil,
+		},
+		{
+			"File sent as a value",
+			map[string]string{"avatar": "png-data"},
+			nil,
+			http.StatusBadRequest, apierror.TypeMissingParam,
+			nil,
+		},
+		{
+			"File too large",
+			nil,
+			[]testFile{{"avatar", "me.png", "image/png", "png-data!"}},
+			http.StatusBadRequest, apierror.TypeInvalidParam,
+			nil,
+		},
+		{
+			"Invalid content type",
+			nil,
+			[]testFile{{"avatar", "me.txt", "text/plain", "text"}},
+			http.StatusBadRequest, apierror.TypeInvalidParam,
+			nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			rec := callEndpoint(e, newMultipartRequest(t, tc.values, tc.files))
+			if !assert.Equal(t, tc.code, rec.Code) {
+				return
+			}
+
+			if tc.errCode != "" {
+				err := decodeError(t, rec)
+				assert.Equal(t, tc.errCode, err.Code)
+				assert.Equal(t, "avatar", err.Field)
+				return
+			}
+
+			res := &uploadResult{}
+			if err := json.NewDecoder(rec.Body).Decode(res); err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tc.expected, res)
+		})
+	}
+}
+
+func TestFileParamsNotMultipart(t *testing.T) {
+	e := &router.Endpoint{
+		Verb:    "POST",
+		Path:    "/",
+		Handler: handlerUpload,
+		Params:  &uploadParams{},
+	}
+
+	r := httptest.NewRequest("POST", "/", strings.NewReader("title=hello&avatar=png-data"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	rec := callEndpoint(e, r)
+	if assert.Equal(t, http.StatusBadRequest, rec.Code) {
+		err := decodeError(t, rec)
+		assert.Equal(t, apierror.TypeMissingParam, err.Code)
+		assert.Equal(t, "avatar", err.Field)
+	}
+}
+
+func TestFileParamsUnsupportedType(t *testing.T) {
+	e := &router.Endpoint{
+		Verb:    "POST",
+		Path:    "/",
+		Handler: func(req *router.Request) { req.NoContent() },
+		Params: &struct {
+			Avatar string `from:"file" json:"avatar"`
+		}{},
+	}
+
+	rec := callEndpoint(e, newMultipartRequest(t, nil, []testFile{{"avatar", "me.png", "image/png", "png-data"}}))
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+}
+
+func TestFileParamsMaxBodySize(t *testing.T) {
+	e := &router.Endpoint{
+		Verb:        "POST",
+		Path:        "/",
+		Handler:     handlerUpload,
+		Params:      &uploadParams{},
+		MaxBodySize: 64,
+	}
+
+	rec := callEndpoint(e, newMultipartRequest(t, nil, []testFile{{"avatar", "me.png", "image/png", strings.Repeat("a", 128)}}))
+	if assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code) {
+		assert.Equal(t, apierror.TypeTooLarge, decodeError(t, rec).Code)
+	}
+}
+
+func TestParamOptionsMaxSize(t *testing.T) {
+	testCases := []struct {
+		tag   string
+		size  int64
+		valid bool
+	}{
+		{`params:"max_size=512"`, 512, true},
+		{`params:"max_size=512B"`, 512, true},
+		{`params:"max_size=2KB"`, 2 << 10, true},
+		{`params:"max_size=2mb"`, 2 << 20, true},
+		{`params:"max_size=1GB"`, 1 << 30, true},
+		{`params:"max_size=0"`, 0, false},
+		{`params:"max_size=-1KB"`, 0, false},
+		{`params:"max_size=MB"`, 0, false},
+		{`params:"max_size=2TB"`, 0, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.tag, func(t *testing.T) {
+			tag := reflect.StructTag(tc.tag)
+			opts, err := router.NewParamOptions(&tag)
+			if !tc.valid {
+				assert.Error(t, err)
+				return
+			}
+
+			if assert.NoError(t, err) {
+				assert.Equal(t, tc.size, opts.MaxSize)
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package router
+
+import (
+	"github.com/Nivl/api.melvin.la/api/app"
+	"github.com/gorilla/mux"
+)
+
+// Group represents a set of endpoints sharing a path prefix and some
+// middlewares. The middlewares of a group are ran before the ones of its
+// subgroups
+type Group struct {
+	Router      *mux.Router
+	App         *app.Context
+	Middlewares Middlewares
+}
+
+// NewGroup returns a group registering its endpoints on the given router,
+// to be handled by the given app
+func NewGroup(r *mux.Router, a *app.Context, middlewares ...Middleware) *Group {
+	return &Group{
+		Router:      r,
+		App:         a,
+		Middlewares: Middlewares(middlewares),
+	}
+}
+
+// Group returns a subgroup handling the endpoints under the given prefix.
+// The given middlewares only apply to the subgroup, and are ran after the
+// ones of g
+func (g *Group) Group(prefix string, middlewares ...Middleware) *Group {
+	return &Group{
+		Router:      g.Router.PathPrefix(prefix).Subrouter(),
+		App:         g.App,
+		Middlewares: g.Middlewares.Append(middlewares...),
+	}
+}
+
+// Activate registers the given endpoints on the group
+func (g *Group) Activate(endpoints Endpoints) {
+	endpoints.Activate(g.Router, g.App, g.Middlewares...)
+}
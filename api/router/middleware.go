@@ -0,0 +1,58 @@
+package router
+
+// Middleware wraps a RouteHandler to run code before and/or after it.
+// A middleware can short-circuit the request by not calling next
+//
+//	func Timer(next router.RouteHandler) router.RouteHandler {
+//		return func(req *router.Request) {
+//			start := time.Now()
+//			next(req)
+//			logger.Infof("%s took %s", req.Request.URL, time.Since(start))
+//		}
+//	}
+type Middleware func(next RouteHandler) RouteHandler
+
+// Middlewares represents a list of middlewares, ran in order
+type Middlewares []Middleware
+
+// Append returns a new list containing the current middlewares followed by
+// the given ones. The current list is not modified
+func (m Middlewares) Append(middlewares ...Middleware) Middlewares {
+	output := make(Middlewares, 0, len(m)+len(middlewares))
+	output = append(output, m...)
+	return append(output, middlewares...)
+}
+
+// Then returns a RouteHandler running all the middlewares before h.
+// The first middleware of the list is the first one to be called
+func (m Middlewares) Then(h RouteHandler) RouteHandler {
+	for i := len(m) - 1; i >= 0; i-- {
+		h = m[i](h)
+	}
+	return h
+}
+
+// Before returns a middleware running fn before the handler. If fn returns
+// an error, the error is sent to the client and the handler is not called
+func Before(fn func(req *Request) error) Middleware {
+	return func(next RouteHandler) RouteHandler {
+		return func(req *Request) {
+			if err := fn(req); err != nil {
+				req.Error(err)
+				return
+			}
+			next(req)
+		}
+	}
+}
+
+// After returns a middleware running fn after the handler, even if the
+// request has been short-circuited by a middleware coming after it
+func After(fn func(req *Request)) Middleware {
+	return func(next RouteHandler) RouteHandler {
+		return func(req *Request) {
+			next(req)
+			fn(req)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package router_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Nivl/api.melvin.la/api/apierror"
+	"github.com/Nivl/api.melvin.la/api/router"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+// testUser is a router.User having all the permissions
+type testUser struct{}
+
+func (u *testUser) UserID() string              { return "user" }
+func (u *testUser) HasPermission(p string) bool { return true }
+
+type testParams struct {
+	Name string `from:"query" json:"name"`
+}
+
+// record returns a middleware adding name to calls
+func record(calls *[]string, name string) router.Middleware {
+	return router.Before(func(req *router.Request) error {
+		*calls = append(*calls, name)
+		return nil
+	})
+}
+
+// serve registers e in a subgroup of a router, and sends it a request
+func serve(e *router.Endpoint, root, sub []router.Middleware) *httptest.ResponseRecorder {
+	r := mux.NewRouter()
+	g := router.NewGroup(r, newLoggedApp(&bytes.Buffer{}), root...)
+	g.Group("/sub", sub...).Activate(router.Endpoints{e})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest("GET", "/sub/items?name=value", nil))
+	return rec
+}
+
+func TestMiddlewaresOrder(t *testing.T) {
+	calls := []string{}
+	e := &router.Endpoint{
+		Verb:   "GET",
+		Path:   "/items",
+		Params: &testParams{},
+		Auth: func(req *router.Request) (router.User, error) {
+			calls = append(calls, "auth")
+			return &testUser{}, nil
+		},
+		Middlewares: []router.Middleware{
+			router.Before(func(req *router.Request) error {
+				calls = append(calls, "endpoint")
+
+				// The endpoint middlewares need to see the user and the params
+				assert.NotNil(t, req.User)
+				if assert.IsType(t, &testParams{}, req.Params) {
+					assert.Equal(t, "value", req.Params.(*testParams).Name)
+				}
+				return nil
+			}),
+			router.After(func(req *router.Request) {
+				calls = append(calls, "after")
+			}),
+		},
+		Handler: func(req *router.Request) {
+			calls = append(calls, "handler")
+			req.NoContent()
+		},
+	}
+
+	root := []router.Middleware{record(&calls, "root")}
+	sub := []router.Middleware{record(&calls, "sub")}
+	rec := serve(e, root, sub)
+
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, []string{"root", "sub", "auth", "endpoint", "handler", "after"}, calls)
+}
+
+func TestMiddlewaresShortCircuit(t *testing.T) {
+	var calls []string
+	after := router.After(func(req *router.Request) {
+		calls = append(calls, "after")
+	})
+	fail := router.Before(func(req *router.Request) error {
+		return apierror.NewForbidden("nope")
+	})
+
+	testCases := []struct {
+		description string
+		root        []router.Middleware
+		sub         []router.Middleware
+		endpoint    []router.Middleware
+		authFails   bool
+		code        int
+		calls       []string
+	}{
+		{"Root middleware", []router.Middleware{fail}, nil, nil, false, http.StatusForbidden, []string{}},
+		{"Subgroup middleware", []router.Middleware{after}, []router.Middleware{fail}, nil, false, http.StatusForbidden, []string{"after"}},
+		{"Failed authentication", nil, []router.Middleware{after}, nil, true, http.StatusUnauthorized, []string{"after"}},
+		{"Endpoint middleware", nil, []router.Middleware{after}, []router.Middleware{fail}, false, http.StatusForbidden, []string{"auth", "after"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			calls = []string{}
+			e := &router.Endpoint{
+				Verb: "GET",
+				Path: "/items",
+				Auth: func(req *router.Request) (router.User, error) {
+					if tc.authFails {
+						return nil, apierror.NewUnauthorized("invalid token")
+					}
+					calls = append(calls, "auth")
+					return &testUser{}, nil
+				},
+				Middlewares: tc.endpoint,
+				Handler: func(req *router.Request) {
+					calls = append(calls, "handler")
+					req.NoContent()
+				},
+			}
+
+			rec := serve(e, tc.root, tc.sub)
+			assert.Equal(t, tc.code, rec.Code)
+			assert.Equal(t, tc.calls, calls)
+		})
+	}
+}
@@ -1,9 +1,13 @@
 package router
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"net/http"
 	"net/url"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -27,17 +31,32 @@ type ParamOptions struct {
 	// Trim means the field needs to be trimmed before being retrieved and checked
 	// params:"trim"
 	Trim bool
+
+	// Constraints contains the list of rules the value of the field needs to
+	// satisfy. See the constraints.go file for the list of available rules
+	// params:"max_len=255,slug"
+	Constraints []Constraint
+
+	// MaxSize contains the maximum size of a file, in bytes. B, KB, MB and GB
+	// can be used as unit
+	// params:"max_size=2MB"
+	MaxSize int64
+
+	// Sensitive means the value of the field should never be logged nor
+	// sent back in an error, like a password
+	// params:"sensitive"
+	Sensitive bool
 }
 
 // NewParamOptions returns a ParamOptions from a StructTag
-func NewParamOptions(tags *reflect.StructTag) *ParamOptions {
+func NewParamOptions(tags *reflect.StructTag) (*ParamOptions, error) {
 	output := &ParamOptions{}
 
 	// We use the json tag to get the field name
 	jsonOpts := strings.Split(tags.Get("json"), ",")
 	if len(jsonOpts) > 0 {
 		if jsonOpts[0] == "-" {
-			return &ParamOptions{Ignore: true}
+			return &ParamOptions{Ignore: true}, nil
 		}
 
 		output.Name = jsonOpts[0]
@@ -48,19 +67,124 @@ func NewParamOptions(tags *reflect.StructTag) *ParamOptions {
 	nbOptions := len(opts)
 	for i := 0; i < nbOptions; i++ {
 		switch opts[i] {
+		case "":
 		case "required":
 			output.Required = true
 		case "trim":
 			output.Trim = true
+		case "sensitive":
+			output.Sensitive = true
+		default:
+			name, arg := opts[i], ""
+			if pos := strings.Index(opts[i], "="); pos != -1 {
+				name, arg = opts[i][:pos], opts[i][pos+1:]
+			}
+
+			if name == "max_size" {
+				size, err := parseSize(arg)
+				if err != nil {
+					return nil, err
+				}
+				output.MaxSize = size
+				continue
+			}
+
+			// A regexp may contain commas, so it uses the rest of the tag
+			if name == "regexp" {
+				arg = strings.Join(append([]string{arg}, opts[i+1:]...), ",")
+				i = nbOptions
+			}
+
+			c, err := NewConstraint(name, arg)
+			if err != nil {
+				return nil, err
+			}
+			output.Constraints = append(output.Constraints, c)
+		}
+	}
+
+	return output, nil
+}
+
+// Validate checks that the given value satisfies all the constraints of the
+// field
+func (opts *ParamOptions) Validate(value string) error {
+	for _, c := range opts.Constraints {
+		if err := c.Check(value); err != nil {
+			return opts.newInvalidValueError(value, err)
 		}
 	}
+	return nil
+}
 
+// newInvalidValueError returns an invalid_param error for the given value.
+// The value is left out of the message if the field is sensitive
+func (opts *ParamOptions) newInvalidValueError(value string, err error) error {
+	if opts.Sensitive {
+		return apierror.NewInvalidParam(opts.Name, "value for parameter [%s] is invalid: %s", opts.Name, err.Error())
+	}
+	return apierror.NewInvalidParam(opts.Name, "value [%s] for parameter [%s] is invalid: %s", value, opts.Name, err.Error())
+}
+
+// loggableParams returns a copy of the given params struct that can be
+// logged, with the sensitive fields redacted
+func loggableParams(params interface{}) interface{} {
+	value := reflect.ValueOf(params)
+	if value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+
+	if value.Kind() != reflect.Struct {
+		return params
+	}
+
+	output := map[string]interface{}{}
+	for i := 0; i < value.NumField(); i++ {
+		info := value.Type().Field(i)
+		if info.PkgPath != "" {
+			continue
+		}
+
+		opts, err := NewParamOptions(&info.Tag)
+		if err != nil || opts.Ignore {
+			continue
+		}
+
+		name := opts.Name
+		if name == "" {
+			name = info.Name
+		}
+
+		if opts.Sensitive {
+			output[name] = "[redacted]"
+			continue
+		}
+		output[name] = value.Field(i).Interface()
+	}
 	return output
 }
 
 // ParseParams will parse the params from the given request, and store them
-// into the endpoint
+// into the endpoint. When several params are invalid, they are all reported
+// at once in a single validation error.
+// The source of a param is set using the "from" tag, and can be url (default),
+// query, form, file, header or cookie
+//
+//	Lang string `from:"header" json:"Accept-Language" default:"en"`
 func (r *Request) ParseParams() error {
+	return r.parseParams(false)
+}
+
+// ParseParamsFirstError works like ParseParams but returns the error of the
+// first invalid param
+func (r *Request) ParseParamsFirstError() error {
+	return r.parseParams(true)
+}
+
+func (r *Request) parseParams(stopAtFirstError bool) error {
 	params := reflect.ValueOf(r.Params)
 	if params.Kind() == reflect.Ptr {
 		params = params.Elem()
@@ -71,16 +195,37 @@ func (r *Request) ParseParams() error {
 		return err
 	}
 
+	fieldErrors := []*apierror.FieldError{}
+	var firstError error
+	addFieldError := func(err error, source string) error {
+		apiErr, casted := err.(*apierror.ApiError)
+		if stopAtFirstError || !casted || apiErr.Code() != http.StatusBadRequest {
+			return err
+		}
+
+		if firstError == nil {
+			firstError = err
+		}
+
+		fieldErrors = append(fieldErrors, &apierror.FieldError{
+			Field:   apiErr.Field,
+			Source:  source,
+			Code:    apiErr.Type,
+			Message: apiErr.Error(),
+		})
+		return nil
+	}
+
+	// formParams contains the name of all the params that can be set using
+	// the body of the request
+	formParams := map[string]bool{}
+
 	nbParams := params.NumField()
 	for i := 0; i < nbParams; i++ {
 		param := params.Field(i)
 		paramInfo := params.Type().Field(i)
 		tags := paramInfo.Tag
 
-		if param.Kind() == reflect.Ptr {
-			param = param.Elem()
-		}
-
 		// We make sure we can update the value of field
 		if !param.CanSet() {
 			return apierror.NewServerError("Field %s could not be set", paramInfo.Name)
@@ -89,22 +234,93 @@ func (r *Request) ParseParams() error {
 		// We control the type of
 		paramLocation := strings.ToLower(tags.Get("from"))
 		source, found := sources[paramLocation]
-		if !found {
-			source = sources["url"]
+		if !found && paramLocation != "file" {
+			paramLocation = "url"
+			source = sources[paramLocation]
+		}
+
+		// We parse the tag to get the options
+		opts, err := NewParamOptions(&tags)
+		if err != nil {
+			return apierror.NewServerError("invalid params tag on field %s: %s", paramInfo.Name, err.Error())
+		}
+
+		// The tag needs to be ignored
+		if opts.Ignore {
+			continue
+		}
+
+		if opts.Name == "" {
+			// The name of a field rarely matches the one of a header or a cookie
+			// (IfMatch would be read from "Ifmatch"), so it has to be explicit
+			if paramLocation == "header" || paramLocation == "cookie" {
+				return apierror.NewServerError("field %s needs a name in its json tag to be read from a %s", paramInfo.Name, paramLocation)
+			}
+			opts.Name = paramInfo.Name
+		}
+
+		// The name of the headers are case-insensitive
+		if paramLocation == "header" {
+			opts.Name = http.CanonicalHeaderKey(opts.Name)
 		}
 
 		args := &setParamValueArgs{
 			param:     &param,
 			paramInfo: &paramInfo,
 			tags:      &tags,
+			opts:      opts,
 			source:    &source,
 		}
 
-		if err := r.setParamValue(args); err != nil {
-			return err
+		if paramLocation == "form" {
+			formParams[opts.Name] = true
+			args.raw = r.jsonBody
+		}
+
+		setter := r.setParamValue
+		if paramLocation == "file" {
+			setter = r.setParamFile
+		}
+
+		if err := setter(args); err != nil {
+			if err := addFieldError(err, paramLocation); err != nil {
+				return err
+			}
 		}
 	}
 
+	if r.Endpoint != nil && r.Endpoint.DisallowUnknownFields {
+		// The objects of a JSON body are not part of the form values
+		names := []string{}
+		for name := range r.jsonBody {
+			names = append(names, name)
+		}
+		for name := range sources["form"] {
+			if _, found := r.jsonBody[name]; !found {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			if !formParams[name] {
+				if err := addFieldError(apierror.NewUnknownParam(name), "form"); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	// A single invalid param is returned as it is, so its field can be read
+	// without going through the details
+	if len(fieldErrors) == 1 {
+		return firstError
+	}
+
+	if len(fieldErrors) > 1 {
+		return apierror.NewValidationError(fieldErrors)
+	}
+
 	return nil
 }
 
@@ -112,56 +328,214 @@ type setParamValueArgs struct {
 	param     *reflect.Value
 	paramInfo *reflect.StructField
 	tags      *reflect.StructTag
+	opts      *ParamOptions
 	source    *url.Values
+
+	// raw contains the raw JSON values of the body, used to decode the params
+	// that are not scalars
+	raw map[string]json.RawMessage
 }
 
 func (r *Request) setParamValue(args *setParamValueArgs) error {
-	// We parse the tag to get the options
-	opts := NewParamOptions(args.tags)
+	opts := args.opts
 	defaultValue := args.tags.Get("default")
 
-	// The tag needs to be ignored
-	if opts.Ignore {
-		return nil
+	if !isSupportedType(args.param.Type()) {
+		return apierror.NewServerError("parameter [%s] has an unsupported type", opts.Name)
 	}
 
-	if opts.Name == "" {
-		opts.Name = args.paramInfo.Name
+	if isJSONType(args.param.Type()) {
+		return r.setParamJSONValue(args)
 	}
 
 	// We get the value and apply the transformations
-	value := args.source.Get(opts.Name)
+	values, provided := (*args.source)[opts.Name]
+
+	// Objects cannot be converted into a scalar
+	if raw, found := args.raw[opts.Name]; !provided && found && !isJSONNull(raw) {
+		return apierror.NewInvalidParam(opts.Name, "value of parameter [%s] has an unsupported type", opts.Name)
+	}
+
+	if args.param.Kind() == reflect.Slice && !isSpecialType(args.param.Type()) {
+		return setParamSliceValue(args.param, values, opts)
+	}
+
+	value := ""
+	if len(values) > 0 {
+		value = values[0]
+	}
+
 	if opts.Trim {
 		value = strings.TrimSpace(value)
 	}
 
 	if value == "" {
-		value = defaultValue
 		if opts.Required {
-			return apierror.NewBadRequest("parameter [%s] missing", opts.Name)
+			return apierror.NewMissingParam(opts.Name)
+		}
+
+		if defaultValue != "" {
+			value = defaultValue
+			provided = true
 		}
 	}
 
-	// We now set the value in the struct
+	param := args.param
+	if param.Kind() == reflect.Ptr {
+		// Pointers are used for optional params. They are left to nil when the
+		// param is missing, which makes it possible to tell apart a missing
+		// param and a param set to an empty string
+		if !provided || (value == "" && !acceptsEmptyValue(param.Type().Elem())) {
+			return nil
+		}
+
+		ptr := reflect.New(param.Type().Elem())
+		param.Set(ptr)
+		elem := ptr.Elem()
+		param = &elem
+	}
+
+	// We now set the value in the struct. An empty value is only set (and
+	// checked) if the type accepts it, otherwise the param is considered
+	// missing
+	if value == "" && !acceptsEmptyValue(param.Type()) {
+		return nil
+	}
+
 	if value != "" {
-		var errorMsg = fmt.Sprintf("value [%s] for parameter [%s] is invalid", value, opts.Name)
+		if err := setValue(*param, value); err != nil {
+			return opts.newInvalidValueError(value, err)
+		}
+	}
 
-		switch args.param.Kind() {
-		case reflect.Bool:
-			v, err := strconv.ParseBool(value)
-			if err != nil {
-				return apierror.NewBadRequest(errorMsg)
-			}
-			args.param.SetBool(v)
-		case reflect.String:
-			args.param.SetString(value)
-		case reflect.Int:
-			v, err := strconv.ParseInt(value, 10, 64)
-			if err != nil {
-				return apierror.NewBadRequest(errorMsg)
-			}
-			args.param.SetInt(v)
+	// The constraints are checked as soon as the param is sent, so an empty
+	// string can be rejected
+	if provided {
+		return opts.Validate(value)
+	}
+	return nil
+}
+
+// setParamSliceValue sets all the values of a param to a slice
+func setParamSliceValue(param *reflect.Value, values []string, opts *ParamOptions) error {
+	if len(values) == 0 && opts.Required {
+		return apierror.NewMissingParam(opts.Name)
+	}
+
+	// The slice is left to nil when the param is missing, which makes it
+	// possible to tell apart a missing param and an empty list
+	if values == nil {
+		return nil
+	}
+
+	list := reflect.MakeSlice(param.Type(), len(values), len(values))
+	for i, value := range values {
+		if opts.Trim {
+			value = strings.TrimSpace(value)
+		}
+
+		if err := setValue(list.Index(i), value); err != nil {
+			return opts.newInvalidValueError(value, err)
+		}
+
+		if err := opts.Validate(value); err != nil {
+			return err
 		}
 	}
+
+	param.Set(list)
 	return nil
 }
+
+// setParamJSONValue decodes the JSON value of a param that is not a scalar,
+// like an object or a list of objects
+func (r *Request) setParamJSONValue(args *setParamValueArgs) error {
+	opts := args.opts
+
+	raw, found := args.raw[opts.Name]
+	if !found || isJSONNull(raw) {
+		if opts.Required {
+			return apierror.NewMissingParam(opts.Name)
+		}
+		return nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	if r.Endpoint != nil && r.Endpoint.DisallowUnknownFields {
+		decoder.DisallowUnknownFields()
+	}
+
+	value := reflect.New(args.param.Type())
+	if err := decoder.Decode(value.Interface()); err != nil {
+		return apierror.NewInvalidParam(opts.Name, "value of parameter [%s] is invalid: %s", opts.Name, err.Error())
+	}
+
+	args.param.Set(value.Elem())
+	return nil
+}
+
+// setParamFile sets the uploaded files to a param. The constraints of the
+// param are checked against the Content-Type of the files
+func (r *Request) setParamFile(args *setParamValueArgs) error {
+	opts := args.opts
+
+	fileType := reflect.TypeOf(&File{})
+	isList := args.param.Type() == reflect.SliceOf(fileType)
+	if args.param.Type() != fileType && !isList {
+		return apierror.NewServerError("parameter [%s] has an unsupported type", opts.Name)
+	}
+
+	files := r.Files(opts.Name)
+	if len(files) == 0 {
+		if opts.Required {
+			return apierror.NewMissingParam(opts.Name)
+		}
+		return nil
+	}
+
+	for _, f := range files {
+		if opts.MaxSize > 0 && f.Size > opts.MaxSize {
+			return apierror.NewInvalidParam(opts.Name, "file [%s] exceeds the maximum size of %d bytes", f.Name, opts.MaxSize)
+		}
+
+		if err := opts.Validate(f.ContentType); err != nil {
+			return err
+		}
+	}
+
+	if isList {
+		args.param.Set(reflect.ValueOf(files))
+	} else {
+		args.param.Set(reflect.ValueOf(files[0]))
+	}
+	return nil
+}
+
+// parseSize parses a size in bytes, using an optional unit (B, KB, MB, GB)
+func parseSize(value string) (int64, error) {
+	units := []struct {
+		suffix string
+		size   int64
+	}{
+		{"GB", 1 << 30},
+		{"MB", 1 << 20},
+		{"KB", 1 << 10},
+		{"B", 1},
+	}
+
+	multiplier := int64(1)
+	number := strings.ToUpper(value)
+	for _, unit := range units {
+		if strings.HasSuffix(number, unit.suffix) {
+			number = strings.TrimSuffix(number, unit.suffix)
+			multiplier = unit.size
+			break
+		}
+	}
+
+	size, err := strconv.ParseInt(number, 10, 64)
+	if err != nil || size <= 0 {
+		return 0, fmt.Errorf("invalid size [%s]", value)
+	}
+	return size * multiplier, nil
+}
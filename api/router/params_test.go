@@ -0,0 +1,201 @@
+package router_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Nivl/api.melvin.la/api/apierror"
+	"github.com/Nivl/api.melvin.la/api/router"
+	"github.com/stretchr/testify/assert"
+)
+
+type headerParams struct {
+	Lang    string  `from:"header" json:"accept-language" default:"en"`
+	IfMatch string  `from:"header" json:"If-Match" params:"required"`
+	Session string  `from:"cookie" json:"session_id"`
+	Theme   *string `from:"cookie" json:"theme"`
+}
+
+func TestParseParamsHeadersAndCookies(t *testing.T) {
+	testCases := []struct {
+		description string
+		headers     map[string]string
+		cookies     map[string]string
+		code        int
+		field       string
+		expected    headerParams
+	}{
+		{
+			"All set",
+			map[string]string{"Accept-Language": "fr", "If-Match": "etag"},
+			map[string]string{"session_id": "abc", "theme": "dark"},
+			0, "",
+			headerParams{Lang: "fr", IfMatch: "etag", Session: "abc", Theme: strPtr("dark")},
+		},
+		{
+			"Headers are case-insensitive",
+			map[string]string{"if-match": "etag"},
+			nil,
+			0, "",
+			headerParams{Lang: "en", IfMatch: "etag"},
+		},
+		{
+			"Missing required header",
+			map[string]string{"Accept-Language": "fr"},
+			nil,
+			http.StatusBadRequest, "If-Match",
+			headerParams{},
+		},
+		{
+			"Cookie names are case-sensitive",
+			map[string]string{"If-Match": "etag"},
+			map[string]string{"SESSION_ID": "abc"},
+			0, "",
+			headerParams{Lang: "en", IfMatch: "etag"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			for k, v := range tc.headers {
+				r.Header.Set(k, v)
+			}
+			for k, v := range tc.cookies {
+				r.AddCookie(&http.Cookie{Name: k, Value: v})
+			}
+
+			params := &headerParams{}
+			req := &router.Request{Request: r, Params: params}
+			err := req.ParseParams()
+
+			if tc.code != 0 {
+				apiErr, ok := err.(*apierror.ApiError)
+				if assert.True(t, ok, "expected an apierror, got %v", err) {
+					assert.Equal(t, tc.code, apiErr.Code())
+					assert.Equal(t, tc.field, apiErr.Field)
+				}
+				return
+			}
+
+			if assert.NoError(t, err) {
+				assert.Equal(t, tc.expected, *params)
+			}
+		})
+	}
+}
+
+func TestParseParamsUnnamedHeader(t *testing.T) {
+	testCases := []struct {
+		description string
+		params      interface{}
+	}{
+		{"Header", &struct {
+			IfMatch string `from:"header"`
+		}{}},
+		{"Cookie", &struct {
+			Session string `from:"cookie"`
+		}{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			r.Header.Set("Ifmatch", "etag")
+			r.AddCookie(&http.Cookie{Name: "Session", Value: "abc"})
+
+			req := &router.Request{Request: r, Params: tc.params}
+			err := req.ParseParams()
+
+			apiErr, ok := err.(apierror.Error)
+			if assert.True(t, ok, "expected an apierror, got %v", err) {
+				assert.Equal(t, http.StatusInternalServerError, apiErr.Code())
+			}
+		})
+	}
+}
+
+func strPtr(s string) *string {
+	return &s
+}
+
+type loginParams struct {
+	Email    string `from:"form" json:"email"`
+	Password string `from:"form" json:"password" params:"sensitive,min_len=8"`
+}
+
+func TestErrorRedactsSensitiveParams(t *testing.T) {
+	var buf bytes.Buffer
+	a := newLoggedApp(&buf)
+
+	req := &router.Request{
+		Request:  httptest.NewRequest("POST", "/", nil),
+		Response: httptest.NewRecorder(),
+		Params:   &loginParams{Email: "user@domain.tld", Password: "hunter22"},
+		Logger:   a.Logger,
+	}
+	req.Error(apierror.NewServerError("could not log in"))
+
+	assert.Contains(t, buf.String(), "user@domain.tld")
+	assert.Contains(t, buf.String(), "[redacted]")
+	assert.NotContains(t, buf.String(), "hunter22")
+}
+
+func TestParseParamsSensitiveInvalidValue(t *testing.T) {
+	r := httptest.NewRequest("POST", "/", strings.NewReader("email=user@domain.tld&password=hunter"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	req := &router.Request{Request: r, Params: &loginParams{}}
+	err := req.ParseParams()
+
+	apiErr, ok := err.(*apierror.ApiError)
+	if assert.True(t, ok, "expected an apierror, got %v", err) {
+		assert.Equal(t, "password", apiErr.Field)
+		assert.NotContains(t, apiErr.Error(), "hunter")
+	}
+}
+
+type emptyParams struct {
+	Name     string  `from:"query" json:"name" params:"trim,min_len=1"`
+	Nickname *string `from:"query" json:"nickname" params:"min_len=2"`
+	Page     int     `from:"query" json:"page" params:"min=1"`
+}
+
+func TestParseParamsEmptyValues(t *testing.T) {
+	testCases := []struct {
+		description string
+		query       string
+		field       string
+	}{
+		{"Missing params", "", ""},
+		{"Empty string", "name=", "name"},
+		{"Blank string", "name=++", "name"},
+		{"Empty pointer", "nickname=", "nickname"},
+		{"Empty number", "page=", ""},
+		{"Valid values", "name=John&nickname=Jo&page=1", ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			req := &router.Request{
+				Request: httptest.NewRequest("GET", "/?"+tc.query, nil),
+				Params:  &emptyParams{},
+			}
+			err := req.ParseParams()
+
+			if tc.field == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			apiErr, ok := err.(*apierror.ApiError)
+			if assert.True(t, ok, "expected an apierror, got %v", err) {
+				assert.Equal(t, apierror.TypeInvalidParam, apiErr.Type)
+				assert.Equal(t, tc.field, apiErr.Field)
+			}
+		})
+	}
+}
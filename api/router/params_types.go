@@ -0,0 +1,151 @@
+package router
+
+import (
+	"encoding"
+	"errors"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/Nivl/api.melvin.la/api/app/helpers"
+	"gopkg.in/mgo.v2/bson"
+)
+
+var (
+	timeType            = reflect.TypeOf(time.Time{})
+	durationType        = reflect.TypeOf(time.Duration(0))
+	objectIDType        = reflect.TypeOf(bson.ObjectId(""))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// isSupportedType returns true if a param of the given type can be parsed.
+// Pointers and slices are supported as long as their elements are.
+// Objects are supported but can only be sent in a JSON body
+func isSupportedType(t reflect.Type) bool {
+	if isSpecialType(t) || isJSONType(t) {
+		return true
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice:
+		// We don't support pointers of pointers, or lists of lists
+		elem := t.Elem()
+		if elem.Kind() == reflect.Ptr || (elem.Kind() == reflect.Slice && !isSpecialType(elem)) {
+			return false
+		}
+		return isSupportedType(elem)
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// isJSONType returns true if a param of the given type can only be set
+// using a JSON body, like a struct or a list of structs
+func isJSONType(t reflect.Type) bool {
+	if isSpecialType(t) {
+		return false
+	}
+
+	switch t.Kind() {
+	case reflect.Struct, reflect.Map, reflect.Interface:
+		return true
+	case reflect.Ptr, reflect.Slice, reflect.Array:
+		return isJSONType(t.Elem())
+	}
+	return false
+}
+
+// isSpecialType returns true if the given type is not parsed using its kind
+func isSpecialType(t reflect.Type) bool {
+	switch t {
+	case timeType, durationType, objectIDType:
+		return true
+	}
+	return reflect.PtrTo(t).Implements(textUnmarshalerType)
+}
+
+// acceptsEmptyValue returns true if an empty string is a valid value for
+// the given type
+func acceptsEmptyValue(t reflect.Type) bool {
+	return t.Kind() == reflect.String && !isSpecialType(t)
+}
+
+// setValue parses the given value and stores it into param. The returned
+// error explains what was expected and can be sent to the clients
+func setValue(param reflect.Value, value string) error {
+	switch param.Type() {
+	case timeType:
+		t, err := helpers.ParseDate(value)
+		if err != nil {
+			return errors.New("expected a date using the ISO8601 or RFC3339 format")
+		}
+		param.Set(reflect.ValueOf(t))
+		return nil
+	case durationType:
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return errors.New("expected a duration, like 1h30m")
+		}
+		param.SetInt(int64(d))
+		return nil
+	case objectIDType:
+		if !bson.IsObjectIdHex(value) {
+			return errors.New("expected an ObjectId")
+		}
+		param.SetString(string(bson.ObjectIdHex(value)))
+		return nil
+	}
+
+	if param.CanAddr() && param.Addr().Type().Implements(textUnmarshalerType) {
+		return param.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(value))
+	}
+
+	switch param.Kind() {
+	case reflect.Bool:
+		// HTML forms send "on" for the checked checkboxes
+		if value == "on" {
+			value = "true"
+		}
+
+		v, err := strconv.ParseBool(value)
+		if err != nil {
+			return errors.New("expected a boolean")
+		}
+		param.SetBool(v)
+	case reflect.String:
+		param.SetString(value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v, err := strconv.ParseInt(value, 10, param.Type().Bits())
+		if err != nil {
+			return numError(err, "expected an integer")
+		}
+		param.SetInt(v)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v, err := strconv.ParseUint(value, 10, param.Type().Bits())
+		if err != nil {
+			return numError(err, "expected a positive integer")
+		}
+		param.SetUint(v)
+	case reflect.Float32, reflect.Float64:
+		v, err := strconv.ParseFloat(value, param.Type().Bits())
+		if err != nil {
+			return numError(err, "expected a number")
+		}
+		param.SetFloat(v)
+	default:
+		return errors.New("unsupported type")
+	}
+	return nil
+}
+
+// numError returns the error to send when a number could not be parsed
+func numError(err error, message string) error {
+	if numErr, ok := err.(*strconv.NumError); ok && numErr.Err == strconv.ErrRange {
+		return errors.New("value out of range")
+	}
+	return errors.New(message)
+}
@@ -0,0 +1,158 @@
+package router_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Nivl/api.melvin.la/api/apierror"
+	"github.com/Nivl/api.melvin.la/api/router"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// upperText is a TextUnmarshaler storing its value in uppercase
+type upperText string
+
+func (u *upperText) UnmarshalText(text []byte) error {
+	if string(text) == "invalid" {
+		return errors.New("expected a valid text")
+	}
+	*u = upperText(strings.ToUpper(string(text)))
+	return nil
+}
+
+type typedParams struct {
+	Int       int             `from:"query" json:"int"`
+	Int8      int8            `from:"query" json:"int8"`
+	Int64     int64           `from:"query" json:"int64"`
+	Uint      uint            `from:"query" json:"uint"`
+	Uint8     uint8           `from:"query" json:"uint8"`
+	Float32   float32         `from:"query" json:"float32"`
+	Float64   float64         `from:"query" json:"float64"`
+	Bool      bool            `from:"query" json:"bool"`
+	Duration  time.Duration   `from:"query" json:"duration"`
+	Time      time.Time       `from:"query" json:"time"`
+	ObjectID  bson.ObjectId   `from:"query" json:"objectid"`
+	Text      upperText       `from:"query" json:"text"`
+	IntPtr    *int            `from:"query" json:"int_ptr"`
+	TextPtr   *upperText      `from:"query" json:"text_ptr"`
+	Ints      []int           `from:"query" json:"ints"`
+	Durations []time.Duration `from:"query" json:"durations"`
+	Texts     []upperText     `from:"query" json:"texts"`
+}
+
+func TestParseParamsTypes(t *testing.T) {
+	date := time.Date(2017, 2, 3, 4, 5, 6, 0, time.UTC)
+	three := 3
+	text := upperText("ABC")
+
+	testCases := []struct {
+		description string
+		query       url.Values
+		field       string
+		expected    interface{}
+		valid       bool
+	}{
+		{"int", url.Values{"int": {"-42"}}, "Int", -42, true},
+		{"int with a float", url.Values{"int": {"4.2"}}, "Int", nil, false},
+		{"int with a text", url.Values{"int": {"four"}}, "Int", nil, false},
+		{"int8 max", url.Values{"int8": {"127"}}, "Int8", int8(127), true},
+		{"int8 overflow", url.Values{"int8": {"128"}}, "Int8", nil, false},
+		{"int8 underflow", url.Values{"int8": {"-129"}}, "Int8", nil, false},
+		{"int64 overflow", url.Values{"int64": {"9223372036854775808"}}, "Int64", nil, false},
+		{"uint", url.Values{"uint": {"42"}}, "Uint", uint(42), true},
+		{"uint negative", url.Values{"uint": {"-1"}}, "Uint", nil, false},
+		{"uint8 max", url.Values{"uint8": {"255"}}, "Uint8", uint8(255), true},
+		{"uint8 overflow", url.Values{"uint8": {"256"}}, "Uint8", nil, false},
+		{"float32", url.Values{"float32": {"1.5"}}, "Float32", float32(1.5), true},
+		{"float32 overflow", url.Values{"float32": {"1e39"}}, "Float32", nil, false},
+		{"float64", url.Values{"float64": {"-2.25e3"}}, "Float64", -2250.0, true},
+		{"float64 with a text", url.Values{"float64": {"two"}}, "Float64", nil, false},
+		{"bool", url.Values{"bool": {"true"}}, "Bool", true, true},
+		{"bool from a checkbox", url.Values{"bool": {"on"}}, "Bool", true, true},
+		{"bool with a number", url.Values{"bool": {"2"}}, "Bool", nil, false},
+		{"duration", url.Values{"duration": {"1h30m"}}, "Duration", 90 * time.Minute, true},
+		{"duration without unit", url.Values{"duration": {"90"}}, "Duration", nil, false},
+		{"time ISO8601", url.Values{"time": {"2017-02-03T04:05:06Z"}}, "Time", date, true},
+		{"time invalid", url.Values{"time": {"03/02/2017"}}, "Time", nil, false},
+		{"objectid", url.Values{"objectid": {"58a4b9e0e2e4b3a7c5d6f7a8"}}, "ObjectID", bson.ObjectIdHex("58a4b9e0e2e4b3a7c5d6f7a8"), true},
+		{"objectid invalid", url.Values{"objectid": {"58a4b9e0"}}, "ObjectID", nil, false},
+		{"TextUnmarshaler", url.Values{"text": {"abc"}}, "Text", text, true},
+		{"TextUnmarshaler error", url.Values{"text": {"invalid"}}, "Text", nil, false},
+		{"pointer", url.Values{"int_ptr": {"3"}}, "IntPtr", &three, true},
+		{"pointer missing", url.Values{}, "IntPtr", (*int)(nil), true},
+		{"pointer empty", url.Values{"int_ptr": {""}}, "IntPtr", (*int)(nil), true},
+		{"pointer to a TextUnmarshaler", url.Values{"text_ptr": {"abc"}}, "TextPtr", &text, true},
+		{"slice", url.Values{"ints": {"1", "-2", "3"}}, "Ints", []int{1, -2, 3}, true},
+		{"slice missing", url.Values{}, "Ints", []int(nil), true},
+		{"slice with an invalid value", url.Values{"ints": {"1", "two"}}, "Ints", nil, false},
+		{"slice of durations", url.Values{"durations": {"1s", "2m"}}, "Durations", []time.Duration{time.Second, 2 * time.Minute}, true},
+		{"slice of TextUnmarshalers", url.Values{"texts": {"a", "b"}}, "Texts", []upperText{"A", "B"}, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			params := &typedParams{}
+			req := &router.Request{
+				Request: httptest.NewRequest("GET", "/?"+tc.query.Encode(), nil),
+				Params:  params,
+			}
+			err := req.ParseParams()
+
+			if !tc.valid {
+				apiErr, ok := err.(*apierror.ApiError)
+				if assert.True(t, ok, "expected an apierror, got %v", err) {
+					assert.Equal(t, http.StatusBadRequest, apiErr.Code())
+					assert.Equal(t, apierror.TypeInvalidParam, apiErr.Type)
+				}
+				return
+			}
+
+			if assert.NoError(t, err) {
+				value := reflect.ValueOf(params).Elem().FieldByName(tc.field).Interface()
+				assert.Equal(t, tc.expected, value)
+			}
+		})
+	}
+}
+
+func TestParseParamsUnsupportedTypes(t *testing.T) {
+	testCases := []struct {
+		description string
+		params      interface{}
+	}{
+		{"Pointer of pointer", &struct {
+			Value **int `from:"query" json:"value"`
+		}{}},
+		{"List of lists", &struct {
+			Value [][]int `from:"query" json:"value"`
+		}{}},
+		{"List of pointers", &struct {
+			Value []*int `from:"query" json:"value"`
+		}{}},
+		{"Channel", &struct {
+			Value chan int `from:"query" json:"value"`
+		}{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			req := &router.Request{
+				Request: httptest.NewRequest("GET", "/?value=1", nil),
+				Params:  tc.params,
+			}
+			err := req.ParseParams()
+
+			apiErr, ok := err.(apierror.Error)
+			if assert.True(t, ok, "expected an apierror, got %v", err) {
+				assert.Equal(t, http.StatusInternalServerError, apiErr.Code())
+			}
+		})
+	}
+}
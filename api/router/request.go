@@ -1,27 +1,54 @@
 package router
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 
+	"github.com/Nivl/api.melvin.la/api/apierror"
+	"github.com/Nivl/api.melvin.la/api/app"
 	"github.com/Nivl/api.melvin.la/api/logger"
 	"github.com/gorilla/mux"
+	mgo "gopkg.in/mgo.v2"
 )
 
 const (
 	ContentTypeJSON          = "application/json"
 	ContentTypeMultipartForm = "multipart/form-data"
+	ContentTypeForm          = "application/x-www-form-urlencoded"
 )
 
+// MaxMemory contains the maximum number of bytes of a multipart body that
+// are stored in memory. The rest of the files are stored on disk
+const MaxMemory = 32 << 20
+
 type Request struct {
 	ID           string              `json:"req_id"`
 	Response     http.ResponseWriter `json:"-"`
 	Request      *http.Request       `json:"-"`
 	Params       interface{}
+	User         User      `json:"-"`
+	Endpoint     *Endpoint `json:"-"`
 	_contentType string
+
+	// jsonBody contains the raw values of the JSON body of the request, since
+	// the body can only be read once
+	jsonBody map[string]json.RawMessage
+
+	// Logger is a logger attaching the ID of the request to all its entries
+	Logger *logger.Logger `json:"-"`
+
+	// App contains the dependencies of the app handling the request
+	App *app.Context `json:"-"`
+
+	// session is a copy of the session of the app, used by this request only
+	session *mgo.Session
 }
 
 func (req *Request) String() string {
@@ -31,13 +58,31 @@ func (req *Request) String() string {
 
 	dump, err := json.Marshal(req)
 	if err != nil {
-		logger.Errorf(err.Error())
+		req.Logger.Error(err.Error())
 		return "failed to parse the request"
 	}
 
 	return string(dump)
 }
 
+// DB returns the database to use during the request. The session of the app
+// is copied on the first call so every request gets its own socket from the
+// pool. The copy is closed once the request has been handled
+func (req *Request) DB() *mgo.Database {
+	if req.session == nil {
+		req.session = req.App.Session.Copy()
+	}
+	return req.session.DB(req.App.DB.Name)
+}
+
+// closeSession releases the session of the request, if any
+func (req *Request) closeSession() {
+	if req.session != nil {
+		req.session.Close()
+		req.session = nil
+	}
+}
+
 // ContentType returns the content type of the current request
 func (req *Request) ContentType() string {
 	if req == nil {
@@ -59,7 +104,7 @@ func (req *Request) ContentType() string {
 
 // MuxVariables returns the URL variables associated to the request
 func (req *Request) MuxVariables() url.Values {
-	var output url.Values
+	output := url.Values{}
 
 	if req == nil {
 		return output
@@ -73,7 +118,10 @@ func (req *Request) MuxVariables() url.Values {
 	return output
 }
 
-// MuxVariables parses and returns the body of the request
+// JSONBody parses and returns the body of the request. Scalars and lists
+// of scalars are returned as strings to be parsed like any other params.
+// Objects are not returned, but their raw value is kept to be decoded
+// directly into the params
 func (req *Request) JSONBody() (url.Values, error) {
 	output := url.Values{}
 
@@ -81,27 +129,112 @@ func (req *Request) JSONBody() (url.Values, error) {
 		return output, nil
 	}
 
-	vars := map[string]string{}
-	if err := json.NewDecoder(req.Request.Body).Decode(&vars); err != nil {
-		return nil, err
+	if req.jsonBody == nil {
+		body := map[string]json.RawMessage{}
+		if err := json.NewDecoder(req.Request.Body).Decode(&body); err != nil {
+			// An empty body is not an error
+			if err != io.EOF {
+				return nil, newBodyError("JSON", err)
+			}
+		}
+		req.jsonBody = body
 	}
 
-	for k, v := range vars {
-		output.Set(k, v)
+	for k, raw := range req.jsonBody {
+		decoder := json.NewDecoder(bytes.NewReader(raw))
+		decoder.UseNumber()
+
+		var v interface{}
+		if err := decoder.Decode(&v); err != nil {
+			return nil, apierror.NewBadRequest("invalid JSON body: %s", err.Error())
+		}
+
+		// null values are treated as missing params
+		if v == nil {
+			continue
+		}
+
+		if list, isList := v.([]interface{}); isList {
+			values := make([]string, 0, len(list))
+			for _, elem := range list {
+				value, ok := jsonScalarToString(elem)
+				if !ok {
+					values = nil
+					break
+				}
+				values = append(values, value)
+			}
+
+			// We set the key even if the list is empty so the param is still
+			// considered as provided
+			if values != nil {
+				output[k] = values
+			}
+			continue
+		}
+
+		if value, ok := jsonScalarToString(v); ok {
+			output.Set(k, value)
+		}
 	}
 
 	return output, nil
 }
 
-// ParamsBySource returns a map of params ordered by their source (url, query, form, ...)
+// newBodyError returns the error to send when the body of a request could
+// not be parsed
+func newBodyError(format string, err error) error {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return apierror.NewTooLarge("the body cannot exceed %d bytes", tooLarge.Limit)
+	}
+	return apierror.NewBadRequest("invalid %s body: %s", format, err.Error())
+}
+
+// isJSONNull returns true if the given raw value is null
+func isJSONNull(raw json.RawMessage) bool {
+	return string(bytes.TrimSpace(raw)) == "null"
+}
+
+// jsonScalarToString returns the string representation of a decoded JSON
+// scalar. false is returned if the value is not a scalar
+func jsonScalarToString(v interface{}) (string, bool) {
+	switch value := v.(type) {
+	case string:
+		return value, true
+	case bool:
+		return strconv.FormatBool(value), true
+	case json.Number:
+		return value.String(), true
+	}
+	return "", false
+}
+
+// ParamsBySource returns a map of params ordered by their source (url, query,
+// form, header, cookie). The headers are indexed by their canonical name
 func (req *Request) ParamsBySource() (map[string]url.Values, error) {
 	params := map[string]url.Values{
-		"url":   req.MuxVariables(),
-		"query": req.Request.URL.Query(),
-		"form":  url.Values{},
+		"url":    req.MuxVariables(),
+		"query":  req.Request.URL.Query(),
+		"form":   url.Values{},
+		"header": url.Values(req.Request.Header),
+		"cookie": url.Values{},
+	}
+
+	for _, cookie := range req.Request.Cookies() {
+		params["cookie"].Add(cookie.Name, cookie.Value)
+	}
+
+	var form url.Values
+	var err error
+
+	switch req.ContentType() {
+	case ContentTypeForm, ContentTypeMultipartForm:
+		form, err = req.FormBody()
+	default:
+		form, err = req.JSONBody()
 	}
 
-	form, err := req.JSONBody()
 	if err != nil {
 		return nil, err
 	}
@@ -110,10 +243,48 @@ func (req *Request) ParamsBySource() (map[string]url.Values, error) {
 	return params, nil
 }
 
+// FormBody parses and returns the body of a urlencoded or multipart request.
+// The uploaded files can be retrieved using Files()
+func (req *Request) FormBody() (url.Values, error) {
+	var err error
+
+	switch req.ContentType() {
+	case ContentTypeForm:
+		err = req.Request.ParseForm()
+	case ContentTypeMultipartForm:
+		err = req.Request.ParseMultipartForm(MaxMemory)
+	default:
+		return url.Values{}, nil
+	}
+
+	if err != nil {
+		return nil, newBodyError("form", err)
+	}
+
+	// PostForm only contains the values of the body
+	return req.Request.PostForm, nil
+}
+
+// Files returns the files uploaded with the given name
+func (req *Request) Files(name string) []*File {
+	if req.Request.MultipartForm == nil {
+		return nil
+	}
+
+	headers := req.Request.MultipartForm.File[name]
+	if len(headers) == 0 {
+		return nil
+	}
+
+	files := make([]*File, len(headers))
+	for i, header := range headers {
+		files[i] = newFile(header)
+	}
+	return files
+}
+
 func (req *Request) handlePanic() {
 	if rec := recover(); rec != nil {
-		req.Response.WriteHeader(http.StatusInternalServerError)
-		req.Response.Write([]byte(`{"error":"Something went wrong"}`))
 		// The recovered panic may not be an error
 		var err error
 		switch val := rec.(type) {
@@ -124,6 +295,8 @@ func (req *Request) handlePanic() {
 		}
 		err = fmt.Errorf("panic: %v", err)
 		// TODO send an email
-		logger.Error(err.Error())
+		req.Logger.Error(err.Error())
+
+		req.RenderJSON(http.StatusInternalServerError, apierror.NewPayload(err, req.ID))
 	}
 }
@@ -0,0 +1,139 @@
+package router_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Nivl/api.melvin.la/api/apierror"
+	"github.com/Nivl/api.melvin.la/api/router"
+	"github.com/stretchr/testify/assert"
+)
+
+type address struct {
+	City string `json:"city"`
+	Zip  string `json:"zip"`
+}
+
+type profileParams struct {
+	Name      string    `from:"form" json:"name" params:"required"`
+	Age       *int      `from:"form" json:"age"`
+	Admin     bool      `from:"form" json:"admin"`
+	Tags      []string  `from:"form" json:"tags"`
+	Address   *address  `from:"form" json:"address"`
+	Addresses []address `from:"form" json:"addresses"`
+}
+
+// newProfileEndpoint returns an endpoint sending back the params it
+// received
+func newProfileEndpoint(strict bool) *router.Endpoint {
+	return &router.Endpoint{
+		Verb:                  "POST",
+		Path:                  "/",
+		Params:                &profileParams{},
+		DisallowUnknownFields: strict,
+		MaxBodySize:           128,
+		Handler: func(req *router.Request) {
+			req.Ok(req.Params)
+		},
+	}
+}
+
+func newJSONRequest(body string) *http.Request {
+	r := httptest.NewRequest("POST", "/", strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+	return r
+}
+
+func TestJSONBody(t *testing.T) {
+	age := 30
+
+	testCases := []struct {
+		description string
+		body        string
+		expected    *profileParams
+	}{
+		{
+			"Native types",
+			`{"name": "John", "age": 30, "admin": true, "tags": ["a", "b"]}`,
+			&profileParams{Name: "John", Age: &age, Admin: true, Tags: []string{"a", "b"}},
+		},
+		{
+			"Nested struct",
+			`{"name": "John", "address": {"city": "Paris", "zip": "75001"}}`,
+			&profileParams{Name: "John", Address: &address{City: "Paris", Zip: "75001"}},
+		},
+		{
+			"List of structs",
+			`{"name": "John", "addresses": [{"city": "Paris"}, {"city": "Lyon"}]}`,
+			&profileParams{Name: "John", Addresses: []address{{City: "Paris"}, {City: "Lyon"}}},
+		},
+		{
+			"Null values",
+			`{"name": "John", "age": null, "tags": null, "address": null, "addresses": null}`,
+			&profileParams{Name: "John"},
+		},
+		{
+			"Empty list",
+			`{"name": "John", "tags": []}`,
+			&profileParams{Name: "John", Tags: []string{}},
+		},
+		{
+			"Unknown fields are ignored",
+			`{"name": "John", "nickname": "Jo", "address": {"city": "Paris", "country": "France"}}`,
+			&profileParams{Name: "John", Address: &address{City: "Paris"}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			rec := callEndpoint(newProfileEndpoint(false), newJSONRequest(tc.body))
+			if !assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String()) {
+				return
+			}
+
+			params := &profileParams{}
+			if err := json.NewDecoder(rec.Body).Decode(params); err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tc.expected, params)
+		})
+	}
+}
+
+func TestJSONBodyErrors(t *testing.T) {
+	testCases := []struct {
+		description string
+		body        string
+		strict      bool
+		code        int
+		errCode     string
+		field       string
+	}{
+		{"Invalid JSON", `{"name": "John"`, false, http.StatusBadRequest, apierror.TypeBadRequest, ""},
+		{"Not an object", `["John"]`, false, http.StatusBadRequest, apierror.TypeBadRequest, ""},
+		{"Empty body", ``, false, http.StatusBadRequest, apierror.TypeMissingParam, "name"},
+		{"Null required value", `{"name": null}`, false, http.StatusBadRequest, apierror.TypeMissingParam, "name"},
+		{"Object for a scalar", `{"name": {"first": "John"}}`, false, http.StatusBadRequest, apierror.TypeInvalidParam, "name"},
+		{"Scalar for an object", `{"name": "John", "address": "Paris"}`, false, http.StatusBadRequest, apierror.TypeInvalidParam, "address"},
+		{"Invalid nested value", `{"name": "John", "address": {"city": 75}}`, false, http.StatusBadRequest, apierror.TypeInvalidParam, "address"},
+		{"Unknown field", `{"name": "John", "nickname": "Jo"}`, true, http.StatusBadRequest, apierror.TypeUnknownParam, "nickname"},
+		{"Unknown nested field", `{"name": "John", "address": {"country": "France"}}`, true, http.StatusBadRequest, apierror.TypeInvalidParam, "address"},
+		{"Body too large", `{"name": "` + strings.Repeat("a", 128) + `"}`, false, http.StatusRequestEntityTooLarge, apierror.TypeTooLarge, ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			rec := callEndpoint(newProfileEndpoint(tc.strict), newJSONRequest(tc.body))
+			if !assert.Equal(t, tc.code, rec.Code, rec.Body.String()) {
+				return
+			}
+
+			err := decodeError(t, rec)
+			assert.Equal(t, tc.errCode, err.Code)
+			assert.Equal(t, tc.field, err.Field)
+		})
+	}
+}
@@ -2,14 +2,15 @@ package router
 
 import (
 	"encoding/json"
-	"fmt"
 	"net/http"
 
 	"github.com/Nivl/api.melvin.la/api/apierror"
-	"github.com/Nivl/api.melvin.la/api/app"
 	"github.com/Nivl/api.melvin.la/api/logger"
 )
 
+// Error sends the given error to the client using the apierror.Payload
+// envelope. Errors that are not an *apierror.ApiError are sent as Internal
+// Errors
 func (req *Request) Error(e error) {
 	if req == nil {
 		return
@@ -17,19 +18,19 @@ func (req *Request) Error(e error) {
 
 	err, casted := e.(*apierror.ApiError)
 	if !casted {
-		err = apierror.NewServerError(e.Error()).(*apierror.ApiError)
+		err = apierror.NewServerError("%s", e.Error()).(*apierror.ApiError)
 	}
 
-	switch err.Code() {
-	case http.StatusInternalServerError:
-		logger.Errorf("%s - %s", err.Error(), req)
-		http.Error(req.Response, `{"error":"Something went wrong"}`, http.StatusInternalServerError)
-	default:
-		if app.GetContext().Params.Debug {
-			logger.Errorf("%s - %s", err.Error(), req)
-		}
-		http.Error(req.Response, fmt.Sprintf(`{"error":"%s"}`, err.Error()), err.Code())
+	// Client errors are expected and are only logged when debugging.
+	// The sensitive params, like the passwords, are redacted
+	l := req.Logger.With(logger.Fields{"params": loggableParams(req.Params), "status": err.Code()})
+	if err.Code() >= http.StatusInternalServerError {
+		l.Error(err.Error())
+	} else {
+		l.Debug(err.Error())
 	}
+
+	req.RenderJSON(err.Code(), apierror.NewPayload(err, req.ID))
 }
 
 func (req *Request) NoContent() {
@@ -57,12 +58,21 @@ func (req *Request) Ok(obj interface{}) {
 }
 
 func (req *Request) RenderJSON(code int, obj interface{}) {
-	req.Response.WriteHeader(code)
+	if obj == nil {
+		req.Response.WriteHeader(code)
+		return
+	}
 
-	if obj != nil {
-		if err := json.NewEncoder(req.Response).Encode(obj); err != nil {
-			req.Response.WriteHeader(http.StatusInternalServerError)
-			logger.Errorf("Could not write JSON response: %s", err.Error())
-		}
+	// We encode the object before writing anything, to still be able to
+	// send an error if the encoding fails
+	dump, err := json.Marshal(obj)
+	if err != nil {
+		req.Logger.Errorf("Could not write JSON response: %s", err.Error())
+		code = http.StatusInternalServerError
+		dump, _ = json.Marshal(apierror.NewPayload(err, req.ID))
 	}
+
+	req.Response.Header().Set("Content-Type", ContentTypeJSON+"; charset=utf-8")
+	req.Response.WriteHeader(code)
+	req.Response.Write(append(dump, '\n'))
 }
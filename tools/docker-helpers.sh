@@ -8,8 +8,8 @@ alias ml-log-mongo="docker logs apimelvinla_database_1" # print mongo logs
 
 # Execute any command in the container
 function ml-exec {
-  CMD="cd /go/src/github.com/Nivl/api.melvin.la && $@"
-  docker exec -i -t apimelvinla_api_1 /bin/bash -ic $CMD
+  CMD="cd /go/src/github.com/Nivl/api.melvin.la && $*"
+  docker exec -i -t apimelvinla_api_1 /bin/bash -ic "$CMD"
 }
 
 # Open a bash session
@@ -47,4 +47,18 @@ function ml-tests {
 
   echo "Start testings"
   ml-exec "cd api && go test ./..."
-}
\ No newline at end of file
+}
+# Run a command of the API (ml-api user create -email ..., etc.)
+# The binary is rebuilt first, and the args are quoted to be passed as-is
+function ml-api {
+  local args=""
+  if [ $# -gt 0 ]; then
+    args=$(printf ' %q' "$@")
+  fi
+  ml-exec "go build -o /tmp/api ./api && /tmp/api$args"
+}
+
+# Run the migrations (ml-migrate status, ml-migrate up, etc.)
+function ml-migrate {
+  ml-api migrate "$@"
+}